@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+	"golang.org/x/tools/go/packages"
+)
+
+// tableDirective the doc comment directive naming the table of a struct
+const tableDirective = "//dbgen:table "
+
+// anyType the type of the struct fields mirrored for dbgen that are columns
+var anyType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// pkg a loaded package to generate a repository file for
+type pkg struct {
+	name  string
+	dir   string
+	files []*ast.File
+	info  *types.Info
+}
+
+// table a struct to generate the queries of
+type table struct {
+	Type    string
+	Name    string
+	structT *types.Struct
+}
+
+// load load the packages matching the patterns, ignoring the declarations
+// of previously generated files so stale output does not fail type checking
+func load(patterns []string, cfg config) ([]pkg, error) {
+	loadCfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			mode := parser.ParseComments
+			if filepath.Base(filename) == cfg.output {
+				mode = parser.PackageClauseOnly
+			}
+			return parser.ParseFile(fset, filename, src, mode)
+		},
+	}
+	if cfg.tags != "" {
+		loadCfg.BuildFlags = []string{"-tags=" + cfg.tags}
+	}
+
+	loaded, err := packages.Load(loadCfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if packages.PrintErrors(loaded) > 0 {
+		return nil, fmt.Errorf("failed to load packages")
+	}
+
+	var pkgs []pkg
+	for _, p := range loaded {
+		if len(p.GoFiles) == 0 {
+			continue
+		}
+		pkgs = append(pkgs, pkg{
+			name:  p.Name,
+			dir:   filepath.Dir(p.GoFiles[0]),
+			files: p.Syntax,
+			info:  p.TypesInfo,
+		})
+	}
+
+	return pkgs, nil
+}
+
+// findTables find the structs of a package with a table directive or db
+// tags, in declaration order. Structs embedded in other structs of the
+// package are columns of those tables rather than tables, unless they have
+// a directive.
+func findTables(p pkg) ([]table, error) {
+	var tables []table
+	embedded := embeddedStructs(p)
+
+	for _, file := range p.files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.TypeParams != nil {
+					continue
+				}
+
+				obj := p.info.Defs[ts.Name]
+				if obj == nil {
+					continue
+				}
+				st, ok := obj.Type().Underlying().(*types.Struct)
+				if !ok {
+					continue
+				}
+
+				doc := ts.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+
+				name, ok := directive(doc)
+				if !ok {
+					if !hasDBTags(st) || embedded[obj] {
+						continue
+					}
+					name = plural(snakeCase(ts.Name.Name))
+				}
+				if name == "" {
+					return nil, fmt.Errorf("%s: %s directive without a table name", ts.Name.Name, strings.TrimSpace(tableDirective))
+				}
+
+				tables = append(tables, table{
+					Type:    ts.Name.Name,
+					Name:    name,
+					structT: st,
+				})
+			}
+		}
+	}
+
+	return tables, nil
+}
+
+// embeddedStructs the types of a package embedded without a column name in
+// its structs
+func embeddedStructs(p pkg) map[types.Object]bool {
+	embedded := map[types.Object]bool{}
+
+	for _, obj := range p.info.Defs {
+		if _, ok := obj.(*types.TypeName); !ok {
+			continue
+		}
+		st, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+
+		for i := 0; i < st.NumFields(); i++ {
+			column, _, _ := strings.Cut(reflect.StructTag(st.Tag(i)).Get("db"), ",")
+			if !st.Field(i).Anonymous() || column != "" {
+				continue
+			}
+
+			t := st.Field(i).Type()
+			if ptr, ok := t.(*types.Pointer); ok {
+				t = ptr.Elem()
+			}
+			if named, ok := t.(*types.Named); ok {
+				embedded[named.Obj()] = true
+			}
+		}
+	}
+
+	return embedded
+}
+
+// directive get the table named by the directive of a doc comment
+func directive(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, c := range doc.List {
+		if c.Text == strings.TrimSpace(tableDirective) {
+			return "", true
+		}
+		if strings.HasPrefix(c.Text, tableDirective) {
+			return strings.TrimSpace(strings.TrimPrefix(c.Text, tableDirective)), true
+		}
+	}
+	return "", false
+}
+
+// hasDBTags whether a struct has a field with a db tag
+func hasDBTags(st *types.Struct) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		if _, ok := reflect.StructTag(st.Tag(i)).Lookup("db"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// structOf mirror the db tags of a struct as a runtime type dbgen can
+// reflect over, keeping the embedded and prefixed nested structs dbgen
+// flattens and typing every other field as interface{}
+func structOf(st *types.Struct) reflect.Type {
+	var fields []reflect.StructField
+
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		column, _, _ := strings.Cut(tag.Get("db"), ",")
+
+		var nested *types.Struct
+		t := v.Type()
+		if ptr, ok := t.Underlying().(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		nested, _ = t.Underlying().(*types.Struct)
+
+		switch {
+		case nested != nil && (v.Anonymous() && column == "" || strings.HasSuffix(column, "_")):
+			fields = append(fields, reflect.StructField{
+				Name:      fmt.Sprintf("F%d", i),
+				Type:      structOf(nested),
+				Tag:       tag,
+				Anonymous: v.Anonymous(),
+			})
+		case v.Exported():
+			fields = append(fields, reflect.StructField{
+				Name: fmt.Sprintf("F%d", i),
+				Type: anyType,
+				Tag:  tag,
+			})
+		}
+	}
+
+	return reflect.StructOf(fields)
+}
+
+// queries the SQL of the CRUD functions of a table
+type queries struct {
+	table
+	Get, Insert, Update, Delete string
+}
+
+// buildQueries build the queries of a table with dbgen
+func buildQueries(t table, cfg config) (queries, error) {
+	v := reflect.New(structOf(t.structT)).Interface()
+
+	get := dbgen.NewGet(t.Name, v, dbgen.GetQueryOptions{Dialect: cfg.dialect, Quote: cfg.quote})
+	insert := dbgen.NewInsert(t.Name, v, dbgen.InsertQueryOptions{Dialect: cfg.dialect, Quote: cfg.quote})
+	update := dbgen.NewUpdate(t.Name, v, dbgen.UpdateQueryOptions{Dialect: cfg.dialect, Quote: cfg.quote})
+	del := dbgen.NewDelete(t.Name, v, dbgen.DeleteQueryOptions{Dialect: cfg.dialect, Quote: cfg.quote})
+
+	for _, err := range []error{get.Err(), insert.Err(), update.Err(), del.Err()} {
+		if err != nil {
+			return queries{}, fmt.Errorf("%s: %w", t.Type, err)
+		}
+	}
+
+	return queries{
+		table:  t,
+		Get:    get.String(),
+		Insert: insert.String(),
+		Update: update.String(),
+		Delete: del.String(),
+	}, nil
+}
+
+// generate render the repository file of a package
+func generate(p pkg, tables []table, cfg config) ([]byte, error) {
+	data := struct {
+		Package string
+		Dialect string
+		Tables  []queries
+	}{
+		Package: p.name,
+		Dialect: cfg.dialect.Name(),
+	}
+
+	for _, t := range tables {
+		q, err := buildQueries(t, cfg)
+		if err != nil {
+			return nil, err
+		}
+		data.Tables = append(data.Tables, q)
+	}
+
+	var buf bytes.Buffer
+	if err := repositoryTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+var repositoryTemplate = template.Must(template.New("repository").Funcs(template.FuncMap{
+	"literal": literal,
+}).Parse(`// Code generated by dbgen for {{.Dialect}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+)
+{{range .Tables}}
+// SQL of the queries of {{.Name}}
+const (
+	Get{{.Type}}SQL = {{literal .Get}}
+
+	Insert{{.Type}}SQL = {{literal .Insert}}
+
+	Update{{.Type}}SQL = {{literal .Update}}
+
+	Delete{{.Type}}SQL = {{literal .Delete}}
+)
+
+// Get{{.Type}} select a row of {{.Name}} by its primary key
+func Get{{.Type}}(ctx context.Context, tx dbgen.SelectOneContextQuerier, args ...interface{}) ({{.Type}}, error) {
+	var row {{.Type}}
+	err := tx.SelectOneContext(ctx, Get{{.Type}}SQL, &row, args...)
+	return row, err
+}
+
+// Insert{{.Type}} insert a row of {{.Name}}, scanning returned columns into row
+func Insert{{.Type}}(ctx context.Context, tx dbgen.InsertContextQuerier, row *{{.Type}}) error {
+	return tx.InsertContext(ctx, Insert{{.Type}}SQL, row)
+}
+
+// Update{{.Type}} update a row of {{.Name}} by its primary key, scanning returned columns into row
+func Update{{.Type}}(ctx context.Context, tx dbgen.UpdateContextQuerier, row *{{.Type}}) error {
+	return tx.UpdateContext(ctx, Update{{.Type}}SQL, row)
+}
+
+// Delete{{.Type}} delete rows of {{.Name}} by primary key, returning the number of rows deleted
+func Delete{{.Type}}(ctx context.Context, tx dbgen.DeleteContextQuerier, args ...interface{}) (int64, error) {
+	return tx.DeleteContext(ctx, Delete{{.Type}}SQL, args...)
+}
+{{end}}`))
+
+// literal a Go string literal of a query, raw unless it contains a backtick
+func literal(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+// snakeCase convert a Go identifier to snake case, keeping initialisms
+// together, e.g. HTTPLog to http_log
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// plural the English plural of a snake case table name
+func plural(s string) string {
+	switch {
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"),
+		strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(s[len(s)-2])):
+		return s[:len(s)-1] + "ies"
+	}
+	return s + "s"
+}
@@ -0,0 +1,128 @@
+// Command dbgen generates typed repository files for the structs of Go
+// packages, containing the SQL built by dbgen.NewGet, NewInsert, NewUpdate
+// and NewDelete as constants and CRUD functions running it, so queries are
+// built and checked when generating rather than at startup.
+//
+// Structs with a //dbgen:table directive in their doc comment are generated
+// for the named table. Other structs with db tags are generated for the
+// snake case plural of their name, e.g. UserAccount for user_accounts.
+//
+//	//dbgen:table users
+//	type User struct {
+//		ID   string `db:"id,pk"`
+//		Name string `db:"name"`
+//	}
+//
+// Add a go:generate directive to the package and run go generate:
+//
+//	//go:generate go run github.com/JonathanFejtek/go-dbgen/cmd/dbgen -dialect postgres
+//
+// Usage:
+//
+//	dbgen [flags] [packages]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+)
+
+// dialects the dialects selectable with the -dialect flag
+var dialects = map[string]dbgen.Dialect{
+	"postgres":  dbgen.Postgres,
+	"mysql":     dbgen.MySQL,
+	"sqlite":    dbgen.SQLite,
+	"sqlserver": dbgen.SQLServer,
+}
+
+// quoteModes the quote modes selectable with the -quote flag
+var quoteModes = map[string]dbgen.QuoteMode{
+	"reserved": dbgen.QuoteReserved,
+	"always":   dbgen.QuoteAlways,
+	"never":    dbgen.QuoteNever,
+}
+
+// config options of the generated files
+type config struct {
+	dialect dbgen.Dialect
+	quote   dbgen.QuoteMode
+	output  string
+	tags    string
+}
+
+func main() {
+	dialect := flag.String("dialect", "postgres", "SQL dialect: postgres, mysql, sqlite or sqlserver")
+	quote := flag.String("quote", "reserved", "identifier quoting: reserved, always or never")
+	output := flag.String("output", "dbgen_gen.go", "name of the file generated in each package")
+	tags := flag.String("tags", "", "comma separated build tags used to load packages")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: dbgen [flags] [packages]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cfg := config{
+		dialect: dialects[*dialect],
+		quote:   quoteModes[*quote],
+		output:  *output,
+		tags:    *tags,
+	}
+	if cfg.dialect == nil {
+		fail(fmt.Errorf("unknown dialect %q", *dialect))
+	}
+	if _, ok := quoteModes[*quote]; !ok {
+		fail(fmt.Errorf("unknown quote mode %q", *quote))
+	}
+	if filepath.Base(cfg.output) != cfg.output || !strings.HasSuffix(cfg.output, ".go") {
+		fail(fmt.Errorf("output %q must be a .go file name", cfg.output))
+	}
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	if err := run(patterns, cfg); err != nil {
+		fail(err)
+	}
+}
+
+// run generate the repository file of each package matching the patterns
+func run(patterns []string, cfg config) error {
+	pkgs, err := load(patterns, cfg)
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range pkgs {
+		tables, err := findTables(pkg)
+		if err != nil {
+			return err
+		}
+		if len(tables) == 0 {
+			continue
+		}
+
+		src, err := generate(pkg, tables, cfg)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(pkg.dir, cfg.output)
+		if err := os.WriteFile(path, src, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "dbgen:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+)
+
+func Test_Generate(t *testing.T) {
+
+	cfg := config{
+		dialect: dbgen.Postgres,
+		output:  "dbgen_gen.go",
+	}
+
+	pkgs, err := load([]string{"./testdata/models"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("loaded %d packages, want 1", len(pkgs))
+	}
+
+	tables, err := findTables(pkgs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := generate(pkgs[0], tables, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if os.Getenv("DBGEN_UPDATE_GOLDEN") != "" {
+		if err := os.WriteFile("testdata/models/dbgen_gen.go", got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile("testdata/models/dbgen_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("generated file string = %+v ||  \n want %+v", string(got), string(want))
+	}
+}
+
+func Test_TableNames(t *testing.T) {
+
+	tests := []struct {
+		typeName string
+		want     string
+	}{
+		{"User", "users"},
+		{"LoginAttempt", "login_attempts"},
+		{"HTTPLog", "http_logs"},
+		{"Category", "categories"},
+		{"Address", "addresses"},
+		{"Day", "days"},
+	}
+
+	for _, tt := range tests {
+		if got := plural(snakeCase(tt.typeName)); got != tt.want {
+			t.Errorf("table name of %s string = %+v ||  \n want %+v", tt.typeName, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Code generated by dbgen for postgres. DO NOT EDIT.
+
+package models
+
+import (
+	"context"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+)
+
+// SQL of the queries of users
+const (
+	GetUserSQL = `SELECT users.id, users.name, users.address_street, users.address_city, users.created_at FROM users WHERE id=:id`
+
+	InsertUserSQL = `INSERT INTO users (
+		id, name, address_street, address_city
+	) VALUES (
+		:id, :name, :address_street, :address_city
+	)
+	RETURNING users.id, users.name, users.address_street, users.address_city, users.created_at`
+
+	UpdateUserSQL = `UPDATE users
+	SET
+		name=:name, address_street=:address_street, address_city=:address_city
+	WHERE id=:id
+	RETURNING users.id, users.name, users.address_street, users.address_city, users.created_at`
+
+	DeleteUserSQL = `DELETE FROM users WHERE id=:id`
+)
+
+// GetUser select a row of users by its primary key
+func GetUser(ctx context.Context, tx dbgen.SelectOneContextQuerier, args ...interface{}) (User, error) {
+	var row User
+	err := tx.SelectOneContext(ctx, GetUserSQL, &row, args...)
+	return row, err
+}
+
+// InsertUser insert a row of users, scanning returned columns into row
+func InsertUser(ctx context.Context, tx dbgen.InsertContextQuerier, row *User) error {
+	return tx.InsertContext(ctx, InsertUserSQL, row)
+}
+
+// UpdateUser update a row of users by its primary key, scanning returned columns into row
+func UpdateUser(ctx context.Context, tx dbgen.UpdateContextQuerier, row *User) error {
+	return tx.UpdateContext(ctx, UpdateUserSQL, row)
+}
+
+// DeleteUser delete rows of users by primary key, returning the number of rows deleted
+func DeleteUser(ctx context.Context, tx dbgen.DeleteContextQuerier, args ...interface{}) (int64, error) {
+	return tx.DeleteContext(ctx, DeleteUserSQL, args...)
+}
+
+// SQL of the queries of login_attempts
+const (
+	GetLoginAttemptSQL = `SELECT login_attempts.user_id, login_attempts.at, login_attempts.ok FROM login_attempts WHERE user_id=:user_id AND at=:at`
+
+	InsertLoginAttemptSQL = `INSERT INTO login_attempts (
+		user_id, at, ok
+	) VALUES (
+		:user_id, :at, :ok
+	)
+	RETURNING login_attempts.user_id, login_attempts.at, login_attempts.ok`
+
+	UpdateLoginAttemptSQL = `UPDATE login_attempts
+	SET
+		ok=:ok
+	WHERE user_id=:user_id AND at=:at
+	RETURNING login_attempts.user_id, login_attempts.at, login_attempts.ok`
+
+	DeleteLoginAttemptSQL = `DELETE FROM login_attempts WHERE user_id=:user_id AND at=:at`
+)
+
+// GetLoginAttempt select a row of login_attempts by its primary key
+func GetLoginAttempt(ctx context.Context, tx dbgen.SelectOneContextQuerier, args ...interface{}) (LoginAttempt, error) {
+	var row LoginAttempt
+	err := tx.SelectOneContext(ctx, GetLoginAttemptSQL, &row, args...)
+	return row, err
+}
+
+// InsertLoginAttempt insert a row of login_attempts, scanning returned columns into row
+func InsertLoginAttempt(ctx context.Context, tx dbgen.InsertContextQuerier, row *LoginAttempt) error {
+	return tx.InsertContext(ctx, InsertLoginAttemptSQL, row)
+}
+
+// UpdateLoginAttempt update a row of login_attempts by its primary key, scanning returned columns into row
+func UpdateLoginAttempt(ctx context.Context, tx dbgen.UpdateContextQuerier, row *LoginAttempt) error {
+	return tx.UpdateContext(ctx, UpdateLoginAttemptSQL, row)
+}
+
+// DeleteLoginAttempt delete rows of login_attempts by primary key, returning the number of rows deleted
+func DeleteLoginAttempt(ctx context.Context, tx dbgen.DeleteContextQuerier, args ...interface{}) (int64, error) {
+	return tx.DeleteContext(ctx, DeleteLoginAttemptSQL, args...)
+}
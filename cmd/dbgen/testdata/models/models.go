@@ -0,0 +1,37 @@
+// Package models the structs generated by the dbgen command tests
+package models
+
+import "time"
+
+//go:generate go run github.com/JonathanFejtek/go-dbgen/cmd/dbgen -dialect postgres
+
+// Timestamps columns shared by tables
+type Timestamps struct {
+	CreatedAt time.Time `db:"created_at,readonly"`
+}
+
+// User a row of users
+//
+//dbgen:table users
+type User struct {
+	ID      string `db:"id,pk"`
+	Name    string `db:"name"`
+	Address struct {
+		Street string `db:"street"`
+		City   string `db:"city"`
+	} `db:"address_"`
+	Timestamps
+	password string
+}
+
+// LoginAttempt a row of login_attempts
+type LoginAttempt struct {
+	UserID string    `db:"user_id,pk"`
+	At     time.Time `db:"at,pk"`
+	Ok     bool      `db:"ok"`
+}
+
+// session is not a table
+type session struct {
+	token string
+}
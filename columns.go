@@ -9,6 +9,8 @@ import (
 type Columns struct {
 	TableName string
 	Fields    []string
+	// Quoter quotes the identifiers of the formatted columns
+	Quoter Quoter
 }
 
 // Omit omit columns from the set
@@ -17,6 +19,7 @@ func (cc Columns) Omit(fields ...string) Columns {
 	return Columns{
 		TableName: cc.TableName,
 		Fields:    newFields,
+		Quoter:    cc.Quoter,
 	}
 }
 
@@ -29,6 +32,7 @@ func (cc Columns) Add(fields ...string) Columns {
 	return Columns{
 		TableName: cc.TableName,
 		Fields:    added,
+		Quoter:    cc.Quoter,
 	}
 }
 
@@ -40,6 +44,7 @@ func (cc Columns) Set(fields ...string) Columns {
 	return Columns{
 		TableName: cc.TableName,
 		Fields:    added,
+		Quoter:    cc.Quoter,
 	}
 }
 
@@ -48,6 +53,20 @@ func (cc Columns) Joined() string {
 	return strings.Join(cc.Fields, ", ")
 }
 
+// Quoted get the columns with their identifiers quoted as required
+func (cc Columns) Quoted() Columns {
+	var params []string
+	for _, c := range cc.Fields {
+		params = append(params, cc.Quoter.Ident(c))
+	}
+
+	return Columns{
+		TableName: cc.TableName,
+		Fields:    params,
+		Quoter:    cc.Quoter,
+	}
+}
+
 // AsSelects get the columns formatted to be selected from their parent table
 // suitable for SELECT and RETURNING statements
 func (cc Columns) AsSelects() Columns {
@@ -56,7 +75,7 @@ func (cc Columns) AsSelects() Columns {
 		params = append(
 			params,
 			fmt.Sprintf(
-				"%s.%s", cc.TableName, c,
+				"%s.%s", cc.Quoter.Ident(cc.TableName), cc.Quoter.Ident(c),
 			),
 		)
 	}
@@ -64,6 +83,27 @@ func (cc Columns) AsSelects() Columns {
 	return Columns{
 		TableName: cc.TableName,
 		Fields:    params,
+		Quoter:    cc.Quoter,
+	}
+}
+
+// AsQualified get the columns qualified by a table alias or pseudo table,
+// such as INSERTED for SQL Server OUTPUT clauses
+func (cc Columns) AsQualified(qualifier string) Columns {
+	var params []string
+	for _, c := range cc.Fields {
+		params = append(
+			params,
+			fmt.Sprintf(
+				"%s.%s", qualifier, cc.Quoter.Ident(c),
+			),
+		)
+	}
+
+	return Columns{
+		TableName: cc.TableName,
+		Fields:    params,
+		Quoter:    cc.Quoter,
 	}
 }
 
@@ -82,6 +122,27 @@ func (cc Columns) AsParams() Columns {
 	return Columns{
 		TableName: cc.TableName,
 		Fields:    params,
+		Quoter:    cc.Quoter,
+	}
+}
+
+// AsIndexedParams get the columns formatted as query template parameters
+// suffixed with a row index, suitable for multi-row statements
+func (cc Columns) AsIndexedParams(index int) Columns {
+	var params []string
+	for _, c := range cc.Fields {
+		params = append(
+			params,
+			fmt.Sprintf(
+				":%s_%d", c, index,
+			),
+		)
+	}
+
+	return Columns{
+		TableName: cc.TableName,
+		Fields:    params,
+		Quoter:    cc.Quoter,
 	}
 }
 
@@ -92,7 +153,33 @@ func (cc Columns) AsAssignments() Columns {
 		params = append(
 			params,
 			fmt.Sprintf(
-				"%s=:%s", c, c,
+				"%s=:%s", cc.Quoter.Ident(c), c,
+			),
+		)
+	}
+
+	return Columns{
+		TableName: cc.TableName,
+		Fields:    params,
+		Quoter:    cc.Quoter,
+	}
+}
+
+// AsExcludedAssignments get the columns formatted as assignments from the
+// row excluded by an upsert conflict
+func (cc Columns) AsExcludedAssignments() Columns {
+	return cc.AsAssignmentsFrom("EXCLUDED")
+}
+
+// AsAssignmentsFrom get the columns formatted as assignments from the same
+// columns of another table alias or pseudo table
+func (cc Columns) AsAssignmentsFrom(qualifier string) Columns {
+	var params []string
+	for _, c := range cc.Fields {
+		params = append(
+			params,
+			fmt.Sprintf(
+				"%s=%s.%s", cc.Quoter.Ident(c), qualifier, cc.Quoter.Ident(c),
 			),
 		)
 	}
@@ -100,11 +187,12 @@ func (cc Columns) AsAssignments() Columns {
 	return Columns{
 		TableName: cc.TableName,
 		Fields:    params,
+		Quoter:    cc.Quoter,
 	}
 }
 
 func NewFieldBuilder(tableName string, i interface{}) Columns {
-	tags := getTagsByName("db", i)
+	tags, _ := getTagsByName("db", i)
 	return Columns{
 		TableName: tableName,
 		Fields:    tags,
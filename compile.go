@@ -0,0 +1,352 @@
+package dbgen
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PositionalQuerier interface required to build db functions returning rows
+// from compiled queries
+type PositionalQuerier interface {
+	QueryPositional(q string, dest interface{}, args ...interface{}) error
+}
+
+// PositionalExecer interface required to build db functions not returning
+// rows from compiled queries
+type PositionalExecer interface {
+	ExecPositional(q string, args ...interface{}) (int64, error)
+}
+
+// PositionalContextQuerier interface required to build context aware db
+// functions returning rows from compiled queries
+type PositionalContextQuerier interface {
+	QueryPositionalContext(ctx context.Context, q string, dest interface{}, args ...interface{}) error
+}
+
+// PositionalContextExecer interface required to build context aware db
+// functions not returning rows from compiled queries
+type PositionalContextExecer interface {
+	ExecPositionalContext(ctx context.Context, q string, args ...interface{}) (int64, error)
+}
+
+// CompiledQuery a query rewritten from named parameters to the positional
+// parameters of a dialect
+type CompiledQuery struct {
+	// SQL the query with positional parameters
+	SQL string
+	// Params the name of the parameter bound to each positional parameter
+	Params []string
+	// Err the error building the query it was compiled from, returned when
+	// binding its arguments
+	Err error
+	// named the query with named parameters, expanded when list parameters
+	// are bound to slices
+	named   string
+	dialect Dialect
+	lists   *listExpander
+	// source the query compiled, whose name, table and middleware are passed
+	// to the middleware run by the db functions, and op its operation
+	source query
+	op     Operation
+}
+
+// Compile rewrite a query with named parameters (:name) into the positional
+// parameters of a dialect. Quoted strings, quoted identifiers and Postgres
+// type casts (::type) are left untouched.
+func Compile(query string, d Dialect) CompiledQuery {
+	c := compilePositional(query, dialectOrDefault(d))
+	c.lists = newListExpander(query, nil)
+	c.source.dialect = c.dialect
+	return c
+}
+
+// compilePositional rewrite the named parameters of a query into the
+// positional parameters of a dialect
+func compilePositional(query string, d Dialect) CompiledQuery {
+	var sb strings.Builder
+	var params []string
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := skipQuoted(query, i, c)
+			sb.WriteString(query[i:end])
+			i = end - 1
+
+		case c == ':' && i+1 < len(query) && query[i+1] == ':':
+			sb.WriteString("::")
+			i++
+
+		case c == ':' && i+1 < len(query) && isParamChar(query[i+1]):
+			end := i + 1
+			for end < len(query) && isParamChar(query[end]) {
+				end++
+			}
+
+			name := query[i+1 : end]
+			params = append(params, name)
+			sb.WriteString(d.Placeholder(name, len(params)))
+			i = end - 1
+
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return CompiledQuery{
+		SQL:     sb.String(),
+		Params:  params,
+		named:   query,
+		dialect: d,
+	}
+}
+
+// Bind get the positional arguments of the query, in order, from the fields
+// of a struct (by db tag) or the entries of a map[string]interface{}. A list
+// parameter, IN (:name), bound to a slice is an error as its positional
+// parameters depend on the length of the slice; the db functions of the
+// compiled query expand it.
+func (c CompiledQuery) Bind(arg interface{}) ([]interface{}, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	if len(c.Params) == 0 {
+		return nil, nil
+	}
+
+	values, err := namedValues(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]interface{}, 0, len(c.Params))
+	for _, name := range c.Params {
+		value, ok := values[name]
+		if !ok {
+			return nil, fmt.Errorf("dbgen: missing value for parameter :%s", name)
+		}
+		if c.lists != nil && c.lists.isList(name) && isList(value) {
+			return nil, fmt.Errorf(
+				"dbgen: list parameter :%s bound to a slice, expanded only by the db functions of the query",
+				name,
+			)
+		}
+		args = append(args, value)
+	}
+
+	return args, nil
+}
+
+// BindArgs get the positional arguments of the query from the arguments
+// given to a db function: a single struct or map is bound by name, otherwise
+// the arguments are the values of the distinct parameters in order
+func (c CompiledQuery) BindArgs(args ...interface{}) ([]interface{}, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	if len(args) == 1 && isNamedArg(args[0]) {
+		if len(c.Params) == 0 {
+			return nil, nil
+		}
+		return c.Bind(args[0])
+	}
+
+	if len(c.Params) == 0 {
+		return args, nil
+	}
+
+	var names []string
+	values := map[string]interface{}{}
+	for _, name := range c.Params {
+		if _, ok := values[name]; !ok {
+			values[name] = nil
+			names = append(names, name)
+		}
+	}
+
+	if len(args) != len(names) {
+		return nil, fmt.Errorf(
+			"dbgen: query has %d parameters, got %d arguments",
+			len(names), len(args),
+		)
+	}
+
+	for i, name := range names {
+		values[name] = args[i]
+	}
+
+	return c.Bind(values)
+}
+
+// FnQuery generate the compiled query as a db function returning rows into
+// dest, binding the query parameters from arg
+func (c CompiledQuery) FnQuery() func(tx PositionalQuerier, dest interface{}, arg interface{}) error {
+	query := c.FnQueryContext()
+	return func(tx PositionalQuerier, dest interface{}, arg interface{}) error {
+		return query(context.Background(), positionalQuerier{tx}, dest, arg)
+	}
+}
+
+// FnExec generate the compiled query as a db function returning the number
+// of rows affected, binding the query parameters from arg
+func (c CompiledQuery) FnExec() func(tx PositionalExecer, arg interface{}) (int64, error) {
+	exec := c.FnExecContext()
+	return func(tx PositionalExecer, arg interface{}) (int64, error) {
+		return exec(context.Background(), positionalExecer{tx}, arg)
+	}
+}
+
+// FnQueryContext generate the compiled query as a db function returning rows
+// into dest, honouring the context
+func (c CompiledQuery) FnQueryContext() func(ctx context.Context, tx PositionalContextQuerier, dest interface{}, arg interface{}) error {
+	return func(ctx context.Context, tx PositionalContextQuerier, dest interface{}, arg interface{}) error {
+		qs, args, err := c.expand(arg)
+		if err != nil {
+			return err
+		}
+		_, err = c.source.run(ctx, c.operation(OpSelect), qs, args, func(ctx context.Context) (Result, error) {
+			err := tx.QueryPositionalContext(ctx, qs, dest, args...)
+			if n := selectedRows(dest); n >= 0 {
+				return Result{Rows: n}, err
+			}
+			return oneRow(err)
+		})
+		return err
+	}
+}
+
+// FnExecContext generate the compiled query as a db function returning the
+// number of rows affected, honouring the context
+func (c CompiledQuery) FnExecContext() func(ctx context.Context, tx PositionalContextExecer, arg interface{}) (int64, error) {
+	return func(ctx context.Context, tx PositionalContextExecer, arg interface{}) (int64, error) {
+		qs, args, err := c.expand(arg)
+		if err != nil {
+			return 0, err
+		}
+		var n int64
+		_, err = c.source.run(ctx, c.operation(OpExec), qs, args, func(ctx context.Context) (Result, error) {
+			n, err = tx.ExecPositionalContext(ctx, qs, args...)
+			return execRows(n, err)
+		})
+		return n, err
+	}
+}
+
+// operation the operation of the query compiled, op for a query compiled
+// with Compile
+func (c CompiledQuery) operation(op Operation) Operation {
+	if c.op != "" {
+		return c.op
+	}
+	return op
+}
+
+// positionalQuerier a PositionalQuerier ignoring the context
+type positionalQuerier struct {
+	tx PositionalQuerier
+}
+
+func (p positionalQuerier) QueryPositionalContext(ctx context.Context, q string, dest interface{}, args ...interface{}) error {
+	return p.tx.QueryPositional(q, dest, args...)
+}
+
+// positionalExecer a PositionalExecer ignoring the context
+type positionalExecer struct {
+	tx PositionalExecer
+}
+
+func (p positionalExecer) ExecPositionalContext(ctx context.Context, q string, args ...interface{}) (int64, error) {
+	return p.tx.ExecPositional(q, args...)
+}
+
+// expand get the query and positional arguments of the compiled query bound
+// from arg, recompiling the query with its list parameters bound to slices
+// expanded
+func (c CompiledQuery) expand(arg interface{}) (string, []interface{}, error) {
+	if c.Err != nil || c.lists == nil {
+		args, err := c.Bind(arg)
+		return c.SQL, args, err
+	}
+
+	qs, args, err := c.lists.expand(c.named, []interface{}{arg})
+	if err != nil {
+		return "", nil, err
+	}
+	if qs == c.named {
+		bound, err := c.Bind(arg)
+		return c.SQL, bound, err
+	}
+
+	expanded := Compile(qs, c.dialect)
+	bound, err := expanded.Bind(args[0])
+	return expanded.SQL, bound, err
+}
+
+// namedValues get the named values of a struct or map argument
+func namedValues(arg interface{}) (map[string]interface{}, error) {
+	switch a := arg.(type) {
+	case map[string]interface{}:
+		return a, nil
+	case nil:
+		return nil, fmt.Errorf("dbgen: no argument to bind parameters from")
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(arg))
+	if v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String {
+		values := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			values[iter.Key().String()] = iter.Value().Interface()
+		}
+		return values, nil
+	}
+
+	return getValuesByTag("db", arg)
+}
+
+// isNamedArg whether an argument holds named values, a struct or string map
+func isNamedArg(arg interface{}) bool {
+	if _, ok := arg.(map[string]interface{}); ok {
+		return true
+	}
+
+	v := reflect.ValueOf(arg)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		return v.Type().Key().Kind() == reflect.String
+	case reflect.Struct:
+		return !isScannable(reflect.PtrTo(v.Type())) && !isValuer(v.Type())
+	}
+	return false
+}
+
+// skipQuoted get the index after the quoted section starting at start,
+// treating a doubled quote as an escaped quote
+func skipQuoted(query string, start int, quote byte) int {
+	for i := start + 1; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(query)
+}
+
+func isParamChar(c byte) bool {
+	return c == '_' ||
+		(c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9')
+}
@@ -0,0 +1,295 @@
+package dbgen
+
+import (
+	"context"
+	"fmt"
+)
+
+// ScalarQuerier interface required to build a count or exists db function,
+// selecting a single column of a single row into dest
+type ScalarQuerier interface {
+	SelectScalar(query string, dest interface{}, args ...interface{}) error
+}
+
+// ScalarContextQuerier interface required to build a context aware count or
+// exists db function
+type ScalarContextQuerier interface {
+	SelectScalarContext(ctx context.Context, query string, dest interface{}, args ...interface{}) error
+}
+
+// MakeCountQueryArgs arguments required to make a count or exists query
+type MakeCountQueryArgs struct {
+	TableName string
+	// WhereClause the where clause of the query, empty to match every row
+	WhereClause string
+	Dialect     Dialect
+	Quoter      Quoter
+}
+
+// CountQuery represents a query counting the rows matching its where clause
+type CountQuery struct {
+	query
+	makeQuery func(args MakeCountQueryArgs) string
+}
+
+// String generate the count query as a string query
+func (q CountQuery) String() string {
+	return q.makeQuery(MakeCountQueryArgs{
+		TableName:   q.tableName,
+		WhereClause: q.whereClause,
+		Dialect:     q.dialect,
+		Quoter:      q.quoter,
+	})
+}
+
+// Err get the error building the count query, returned by its db functions
+func (q CountQuery) Err() error {
+	return q.err
+}
+
+// Compile generate the query with the positional parameters of its dialect
+func (q CountQuery) Compile() CompiledQuery {
+	return q.compile(OpCount, q.String(), q.Err())
+}
+
+// Where set the where clause of the count query, a Predicate or a raw SQL
+// string
+func (q CountQuery) Where(where interface{}) CountQuery {
+	nq := q
+	nq.query = nq.query.where(where)
+	return nq
+}
+
+// Named name the count query, passed to middleware
+func (q CountQuery) Named(name string) CountQuery {
+	nq := q
+	nq.query = nq.query.named(name)
+	return nq
+}
+
+// Use attach middleware to the db functions of the count query
+func (q CountQuery) Use(mw ...Middleware) CountQuery {
+	nq := q
+	nq.query = nq.query.use(mw...)
+	return nq
+}
+
+// Fn generate a db function counting rows
+func (q CountQuery) Fn() func(tx ScalarQuerier, args ...interface{}) (int64, error) {
+	count := q.FnContext()
+	return func(tx ScalarQuerier, args ...interface{}) (int64, error) {
+		return count(context.Background(), scalarQuerier{tx}, args...)
+	}
+}
+
+// FnContext generate a db function counting rows, honouring the context
+func (q CountQuery) FnContext() func(ctx context.Context, tx ScalarContextQuerier, args ...interface{}) (int64, error) {
+	qs := q.String()
+	lists := q.listExpander(qs)
+	return func(ctx context.Context, tx ScalarContextQuerier, args ...interface{}) (int64, error) {
+		if q.err != nil {
+			return 0, q.err
+		}
+		qs, args, err := lists.expand(qs, args)
+		if err != nil {
+			return 0, err
+		}
+		var n int64
+		_, err = q.run(ctx, OpCount, qs, args, func(ctx context.Context) (Result, error) {
+			return oneRow(tx.SelectScalarContext(ctx, qs, &n, args...))
+		})
+		return n, err
+	}
+}
+
+// ExistsQuery represents a query checking whether any row matches its where
+// clause
+type ExistsQuery struct {
+	query
+	makeQuery func(args MakeCountQueryArgs) string
+}
+
+// String generate the exists query as a string query
+func (q ExistsQuery) String() string {
+	return q.makeQuery(MakeCountQueryArgs{
+		TableName:   q.tableName,
+		WhereClause: q.whereClause,
+		Dialect:     q.dialect,
+		Quoter:      q.quoter,
+	})
+}
+
+// Err get the error building the exists query, returned by its db functions
+func (q ExistsQuery) Err() error {
+	return q.err
+}
+
+// Compile generate the query with the positional parameters of its dialect
+func (q ExistsQuery) Compile() CompiledQuery {
+	return q.compile(OpExists, q.String(), q.Err())
+}
+
+// Where set the where clause of the exists query, a Predicate or a raw SQL
+// string
+func (q ExistsQuery) Where(where interface{}) ExistsQuery {
+	nq := q
+	nq.query = nq.query.where(where)
+	return nq
+}
+
+// Named name the exists query, passed to middleware
+func (q ExistsQuery) Named(name string) ExistsQuery {
+	nq := q
+	nq.query = nq.query.named(name)
+	return nq
+}
+
+// Use attach middleware to the db functions of the exists query
+func (q ExistsQuery) Use(mw ...Middleware) ExistsQuery {
+	nq := q
+	nq.query = nq.query.use(mw...)
+	return nq
+}
+
+// Fn generate a db function checking whether any row exists
+func (q ExistsQuery) Fn() func(tx ScalarQuerier, args ...interface{}) (bool, error) {
+	exists := q.FnContext()
+	return func(tx ScalarQuerier, args ...interface{}) (bool, error) {
+		return exists(context.Background(), scalarQuerier{tx}, args...)
+	}
+}
+
+// FnContext generate a db function checking whether any row exists,
+// honouring the context
+func (q ExistsQuery) FnContext() func(ctx context.Context, tx ScalarContextQuerier, args ...interface{}) (bool, error) {
+	qs := q.String()
+	lists := q.listExpander(qs)
+	return func(ctx context.Context, tx ScalarContextQuerier, args ...interface{}) (bool, error) {
+		if q.err != nil {
+			return false, q.err
+		}
+		qs, args, err := lists.expand(qs, args)
+		if err != nil {
+			return false, err
+		}
+		var exists bool
+		_, err = q.run(ctx, OpExists, qs, args, func(ctx context.Context) (Result, error) {
+			return oneRow(tx.SelectScalarContext(ctx, qs, &exists, args...))
+		})
+		return exists, err
+	}
+}
+
+// scalarQuerier a ScalarQuerier ignoring the context
+type scalarQuerier struct {
+	tx ScalarQuerier
+}
+
+func (s scalarQuerier) SelectScalarContext(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
+	return s.tx.SelectScalar(query, dest, args...)
+}
+
+// Count the query counting the rows matching the where clause of the get
+// query, ignoring its ordering, limit and offset. Every row is counted
+// unless a where clause is set, the primary key is not matched by default.
+func (q GetQuery) Count() CountQuery {
+	return CountQuery{
+		query:     q.scalarQuery(),
+		makeQuery: makeCountQuery,
+	}
+}
+
+// Exists the query checking whether any row matches the where clause of the
+// get query, any row of the table unless a where clause is set
+func (q GetQuery) Exists() ExistsQuery {
+	return ExistsQuery{
+		query:     q.scalarQuery(),
+		makeQuery: makeExistsQuery,
+	}
+}
+
+// scalarQuery the query of the get query matching its explicit where clause
+// only, empty to match every row
+func (q GetQuery) scalarQuery() query {
+	nq := q.query
+	nq.primaryKeyErr = nil
+	return nq
+}
+
+// CountQueryOptions optional arguments to create a new count or exists query
+type CountQueryOptions struct {
+	MakeQuery func(args MakeCountQueryArgs) string
+	Dialect   Dialect
+	Quote     QuoteMode
+}
+
+// NewCount construct a new query counting the rows of a table, every row
+// unless a where clause is set
+func NewCount(tableName string, opts ...CountQueryOptions) CountQuery {
+	q := CountQuery{
+		query:     newScalarQuery(tableName, opts...),
+		makeQuery: makeCountQuery,
+	}
+
+	if len(opts) > 0 && opts[0].MakeQuery != nil {
+		q.makeQuery = opts[0].MakeQuery
+	}
+
+	return q
+}
+
+// NewExists construct a new query checking whether a table has any row,
+// matching the where clause if set
+func NewExists(tableName string, opts ...CountQueryOptions) ExistsQuery {
+	q := ExistsQuery{
+		query:     newScalarQuery(tableName, opts...),
+		makeQuery: makeExistsQuery,
+	}
+
+	if len(opts) > 0 && opts[0].MakeQuery != nil {
+		q.makeQuery = opts[0].MakeQuery
+	}
+
+	return q
+}
+
+// newScalarQuery the query of a count or exists query, without a struct
+// to validate the columns of its where clause
+func newScalarQuery(tableName string, opts ...CountQueryOptions) query {
+	var options CountQueryOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	return query{
+		tableName: tableName,
+		dialect:   dialectOrDefault(options.Dialect),
+		quoter: Quoter{
+			Dialect: dialectOrDefault(options.Dialect),
+			Mode:    options.Quote,
+		},
+	}
+}
+
+// makeCountQuery render a count query
+func makeCountQuery(args MakeCountQueryArgs) string {
+	qs := fmt.Sprintf(templCount, args.Quoter.Ident(args.TableName))
+	if args.WhereClause != "" {
+		qs += fmt.Sprintf(templWhere, args.WhereClause)
+	}
+	return qs
+}
+
+// makeExistsQuery render an exists query for the dialect of the args, SQL
+// Server cannot select an EXISTS predicate as a value
+func makeExistsQuery(args MakeCountQueryArgs) string {
+	qs := fmt.Sprintf(templSelectOne, args.Quoter.Ident(args.TableName))
+	if args.WhereClause != "" {
+		qs += fmt.Sprintf(templWhere, args.WhereClause)
+	}
+
+	if dialectOrDefault(args.Dialect).Name() == "sqlserver" {
+		return fmt.Sprintf(templExistsSQLServer, qs)
+	}
+	return fmt.Sprintf(templExists, qs)
+}
@@ -0,0 +1,269 @@
+package dbgen
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ColumnDefinition the definition of a column of a created table
+type ColumnDefinition struct {
+	Name    string
+	Type    string
+	NotNull bool
+	Unique  bool
+	// Default the SQL default value of the column, empty if it has none
+	Default string
+}
+
+// MakeCreateTableQueryArgs arguments required to make a create table query
+type MakeCreateTableQueryArgs struct {
+	TableName   string
+	Columns     []ColumnDefinition
+	PrimaryKey  Columns
+	IfNotExists bool
+	Dialect     Dialect
+	Quoter      Quoter
+}
+
+// CreateTableQuery represents a create table query
+type CreateTableQuery struct {
+	query
+	columns     []ColumnDefinition
+	ifNotExists bool
+	makeQuery   func(args MakeCreateTableQueryArgs) string
+}
+
+// PrimaryKey set the primary key columns of the created table
+func (q CreateTableQuery) PrimaryKey(fields ...string) CreateTableQuery {
+	nq := q
+	nq.query = nq.query.setPrimaryKey(fields...)
+	return nq
+}
+
+// IfNotExists create the table only if it does not exist
+func (q CreateTableQuery) IfNotExists() CreateTableQuery {
+	nq := q
+	nq.ifNotExists = true
+	return nq
+}
+
+// String generate the query as a string
+func (q CreateTableQuery) String() string {
+	return q.makeQuery(MakeCreateTableQueryArgs{
+		TableName:   q.tableName,
+		Columns:     q.columns,
+		PrimaryKey:  q.tablePrimaryKey(),
+		IfNotExists: q.ifNotExists,
+		Dialect:     q.dialect,
+		Quoter:      q.quoter,
+	})
+}
+
+// Drop generate the query dropping the table
+func (q CreateTableQuery) Drop() string {
+	return fmt.Sprintf(templDropTable, "", q.quoter.Ident(q.tableName))
+}
+
+// DropIfExists generate the query dropping the table if it exists
+func (q CreateTableQuery) DropIfExists() string {
+	return fmt.Sprintf(templDropTable, "IF EXISTS ", q.quoter.Ident(q.tableName))
+}
+
+// Err get the error building the create table query, returned by its db
+// functions
+func (q CreateTableQuery) Err() error {
+	return q.err
+}
+
+// Fn generate the query as a db function creating the table
+func (q CreateTableQuery) Fn() func(tx PositionalExecer) error {
+	qs := q.String()
+	return func(tx PositionalExecer) error {
+		if q.err != nil {
+			return q.err
+		}
+		_, err := q.run(context.Background(), OpCreateTable, qs, nil, func(ctx context.Context) (Result, error) {
+			return execRows(tx.ExecPositional(qs))
+		})
+		return err
+	}
+}
+
+// FnContext generate the query as a db function creating the table,
+// honouring the context
+func (q CreateTableQuery) FnContext() func(ctx context.Context, tx PositionalContextExecer) error {
+	qs := q.String()
+	return func(ctx context.Context, tx PositionalContextExecer) error {
+		if q.err != nil {
+			return q.err
+		}
+		_, err := q.run(ctx, OpCreateTable, qs, nil, func(ctx context.Context) (Result, error) {
+			return execRows(tx.ExecPositionalContext(ctx, qs))
+		})
+		return err
+	}
+}
+
+// tablePrimaryKey the primary key of the table, none if the default primary
+// key is not a column
+func (q CreateTableQuery) tablePrimaryKey() Columns {
+	for _, pk := range q.primaryKey.Fields {
+		found := false
+		for _, c := range q.columns {
+			found = found || c.Name == pk
+		}
+		if !found {
+			return q.primaryKey.Set()
+		}
+	}
+	return q.primaryKey
+}
+
+// CreateTableQueryOptions optional arguments to create a new create table
+// query
+type CreateTableQueryOptions struct {
+	MakeQuery func(args MakeCreateTableQueryArgs) string
+	Dialect   Dialect
+	Quote     QuoteMode
+}
+
+// NewCreateTable construct a new create table query, inferring the column
+// types of the struct fields from their Go types. Pointer fields are
+// nullable, and the type, notnull, unique and default tag options override
+// the inferred definition of a column.
+func NewCreateTable(
+	tableName string,
+	i interface{},
+	opts ...CreateTableQueryOptions,
+) CreateTableQuery {
+	fields, err := getFieldsByTag("db", i)
+
+	var options CreateTableQueryOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	dialect := dialectOrDefault(options.Dialect)
+	quoter := Quoter{
+		Dialect: dialect,
+		Mode:    options.Quote,
+	}
+	// a table without a primary key is created without one
+	primaryKey, _ := primaryKeyOf(tableName, fields, quoter)
+
+	columns := make([]ColumnDefinition, 0, len(fields))
+	for _, f := range fields {
+		c, columnErr := columnDefinitionOf(f, dialect)
+		if err == nil {
+			err = columnErr
+		}
+		columns = append(columns, c)
+	}
+
+	q := CreateTableQuery{
+		query: query{
+			tableName:  tableName,
+			columns:    columnsOf(fields, nil),
+			err:        err,
+			dialect:    dialect,
+			quoter:     quoter,
+			primaryKey: primaryKey,
+		},
+		columns:   columns,
+		makeQuery: makeCreateTableQuery,
+	}
+
+	if options.MakeQuery != nil {
+		q.makeQuery = options.MakeQuery
+	}
+
+	return q
+}
+
+// columnDefinitionOf get the column definition of a field
+func columnDefinitionOf(f field, d Dialect) (ColumnDefinition, error) {
+	t, nullable := nullableType(f.typ)
+
+	c := ColumnDefinition{
+		Name:    f.column,
+		Type:    f.options[tagType],
+		NotNull: !nullable || f.options.has(tagNotNull) || f.options.has(tagPrimaryKey),
+		Unique:  f.options.has(tagUnique),
+		Default: f.options[tagDefault],
+	}
+
+	if c.Type == "" {
+		typer, ok := d.(ColumnTyper)
+		if ok {
+			c.Type, ok = typer.ColumnType(t)
+		}
+		if !ok {
+			return c, fmt.Errorf(
+				"dbgen: no %s column type for %s of type %s, set the type tag option",
+				d.Name(), f.column, f.typ,
+			)
+		}
+	}
+
+	return c, nil
+}
+
+// nullableType get the type of a field without pointers or sql.Null
+// wrappers, and whether they make it nullable
+func nullableType(t reflect.Type) (reflect.Type, bool) {
+	nullable := false
+
+	for t.Kind() == reflect.Ptr {
+		t, nullable = t.Elem(), true
+	}
+
+	// sql.NullString, sql.Null[T] and similar structs of a value and a
+	// Valid flag
+	if t.Kind() == reflect.Struct && t.NumField() == 2 &&
+		t.Field(1).Name == "Valid" && t.Field(1).Type.Kind() == reflect.Bool {
+		return t.Field(0).Type, true
+	}
+
+	return t, nullable
+}
+
+// makeCreateTableQuery render a create table query for the dialect of the
+// args
+func makeCreateTableQuery(args MakeCreateTableQueryArgs) string {
+	var defs []string
+	for _, c := range args.Columns {
+		def := args.Quoter.Ident(c.Name) + " " + c.Type
+		if c.NotNull {
+			def += " NOT NULL"
+		}
+		if c.Unique {
+			def += " UNIQUE"
+		}
+		if c.Default != "" {
+			def += " DEFAULT " + c.Default
+		}
+		defs = append(defs, def)
+	}
+
+	if len(args.PrimaryKey.Fields) > 0 {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", args.PrimaryKey.Quoted().Joined()))
+	}
+
+	table := args.Quoter.Ident(args.TableName)
+	body := strings.Join(defs, ",\n\t\t")
+
+	if !args.IfNotExists {
+		return fmt.Sprintf(templCreateTable, "", table, body)
+	}
+	if dialectOrDefault(args.Dialect).Name() == "sqlserver" {
+		return fmt.Sprintf(
+			templCreateTableIfNotExistsSQLServer,
+			strings.ReplaceAll(table, "'", "''"),
+			table,
+			body,
+		)
+	}
+	return fmt.Sprintf(templCreateTable, "IF NOT EXISTS ", table, body)
+}
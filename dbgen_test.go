@@ -1,8 +1,17 @@
 package dbgen
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func Test_NewInsert(t *testing.T) {
@@ -92,6 +101,94 @@ func Test_NewInsert(t *testing.T) {
 	}
 }
 
+type bulkInsertRecorder struct {
+	queries []string
+	args    []map[string]interface{}
+}
+
+func (r *bulkInsertRecorder) InsertMany(q string, args map[string]interface{}) error {
+	r.queries = append(r.queries, q)
+	r.args = append(r.args, args)
+	return nil
+}
+
+func Test_InsertQuery_Many(t *testing.T) {
+
+	type user struct {
+		ID   string `db:"id"`
+		Name string `db:"name"`
+	}
+
+	q := NewInsert("users", user{})
+
+	wantQueryString := fmt.Sprintf(
+		templInsertMany,
+		"users",
+		"id, name",
+		"(:id_0, :name_0),\n\t\t(:id_1, :name_1)",
+	)
+	if qString := q.StringMany(2); qString != wantQueryString {
+		t.Errorf("Insert string = %+v ||  \n want %+v", qString, wantQueryString)
+	}
+
+	users := []user{
+		{ID: "1", Name: "a"},
+		{ID: "2", Name: "b"},
+		{ID: "3", Name: "c"},
+	}
+
+	tx := &bulkInsertRecorder{}
+	if err := q.MaxBindParams(4).FnMany()(tx, users); err != nil {
+		t.Fatalf("FnMany error = %v", err)
+	}
+
+	if len(tx.queries) != 2 {
+		t.Fatalf("FnMany statements = %d, want 2", len(tx.queries))
+	}
+	if tx.queries[0] != q.StringMany(2) || tx.queries[1] != q.StringMany(1) {
+		t.Errorf("FnMany queries = %+v", tx.queries)
+	}
+	if tx.args[0]["name_1"] != "b" || tx.args[1]["id_0"] != "3" {
+		t.Errorf("FnMany args = %+v", tx.args)
+	}
+
+	if err := q.FnMany()(tx, user{}); err == nil {
+		t.Errorf("FnMany expected an error for non-slice values")
+	}
+
+	rowsPerStatement := map[Dialect]int{
+		Postgres:  32767,
+		MySQL:     32767,
+		SQLite:    16383,
+		SQLServer: 1000,
+	}
+	for d, want := range rowsPerStatement {
+		if got := NewInsert("users", user{}, InsertQueryOptions{Dialect: d}).rowsPerStatement(); got != want {
+			t.Errorf("%s rows per statement = %d, want %d", d.Name(), got, want)
+		}
+	}
+	if got := NewInsert("users", user{}, InsertQueryOptions{Dialect: SQLite}).MaxBindParams(999).rowsPerStatement(); got != 499 {
+		t.Errorf("sqlite rows per statement with 999 bind parameters = %d, want 499", got)
+	}
+
+	// a MakeQuery unaware of args.Rows renders single rows only
+	single := func(args MakeInsertQueryArgs) string {
+		return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", args.TableName, args.Values.Joined(), args.Values.AsParams().Joined())
+	}
+	custom := NewInsert("users", user{}, InsertQueryOptions{MakeQuery: single})
+	if custom.String() != "INSERT INTO users (id, name) VALUES (:id, :name)" || custom.StringMany(2) != q.StringMany(2) {
+		t.Errorf("custom insert strings = %+v, %+v", custom.String(), custom.StringMany(2))
+	}
+
+	many := func(args MakeInsertQueryArgs) string {
+		return fmt.Sprintf("INSERT %d INTO %s", args.Rows, args.TableName)
+	}
+	customMany := NewInsert("users", user{}, InsertQueryOptions{MakeQuery: single, MakeManyQuery: many})
+	if got := customMany.StringMany(2); got != "INSERT 2 INTO users" {
+		t.Errorf("custom bulk insert string = %+v ||  \n want %+v", got, "INSERT 2 INTO users")
+	}
+}
+
 func Test_NewUpdate(t *testing.T) {
 
 	type args struct {
@@ -119,7 +216,7 @@ func Test_NewUpdate(t *testing.T) {
 			wantQueryString: fmt.Sprintf(
 				templUpdate,
 				"users",
-				"id=:id, name=:name, email=:email, created_at=:created_at, updated_at=:updated_at",
+				"name=:name, email=:email, created_at=:created_at, updated_at=:updated_at",
 				"id=:id",
 				"users.id, users.name, users.email, users.created_at, users.updated_at"),
 		},
@@ -132,7 +229,7 @@ func Test_NewUpdate(t *testing.T) {
 			wantQueryString: fmt.Sprintf(
 				templUpdate,
 				"users",
-				"id=:id, name=:name, email=:email, created_at=:created_at, updated_at=:updated_at",
+				"name=:name, email=:email, created_at=:created_at, updated_at=:updated_at",
 				"email=:email AND id=:id",
 				"users.id, users.name, users.email, users.created_at, users.updated_at"),
 		},
@@ -158,7 +255,7 @@ func Test_NewUpdate(t *testing.T) {
 			wantQueryString: fmt.Sprintf(
 				templUpdate,
 				"users",
-				"id=:id, name=:name, email=:email, created_at=:created_at, updated_at=:updated_at",
+				"name=:name, email=:email, created_at=:created_at, updated_at=:updated_at",
 				"id=:id",
 				"users.id, users.name, users.email"),
 		},
@@ -332,3 +429,1498 @@ func Test_NewDelete(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewUpsert(t *testing.T) {
+
+	type args struct {
+		upsertQuery UpsertQuery
+	}
+
+	user := struct {
+		ID        string `db:"id"`
+		Name      string `db:"name"`
+		Email     string `db:"email"`
+		CreatedAt string `db:"created_at"`
+		UpdatedAt string `db:"updated_at"`
+	}{}
+	tests := []struct {
+		name            string
+		args            args
+		wantQueryString string
+	}{
+		{
+			name: "basic query",
+			args: args{
+				upsertQuery: NewUpsert("users", user).OnConflict("id"),
+			},
+
+			wantQueryString: fmt.Sprintf(
+				templUpsert,
+				"users",
+				"id, name, email, created_at, updated_at",
+				":id, :name, :email, :created_at, :updated_at",
+				"(id) DO UPDATE SET\n\t\tname=EXCLUDED.name, email=EXCLUDED.email, created_at=EXCLUDED.created_at, updated_at=EXCLUDED.updated_at",
+				"users.id, users.name, users.email, users.created_at, users.updated_at"),
+		},
+		{
+			name: "omit updates",
+			args: args{
+				upsertQuery: NewUpsert("users", user).
+					OnConflict("email").
+					OmitUpdates("id", "created_at"),
+			},
+
+			wantQueryString: fmt.Sprintf(
+				templUpsert,
+				"users",
+				"id, name, email, created_at, updated_at",
+				":id, :name, :email, :created_at, :updated_at",
+				"(email) DO UPDATE SET\n\t\tname=EXCLUDED.name, updated_at=EXCLUDED.updated_at",
+				"users.id, users.name, users.email, users.created_at, users.updated_at"),
+		},
+		{
+			name: "do update set columns",
+			args: args{
+				upsertQuery: NewUpsert("users", user).
+					OnConflict("id").
+					DoUpdate("name", "updated_at").
+					OmitReturns("created_at", "updated_at"),
+			},
+
+			wantQueryString: fmt.Sprintf(
+				templUpsert,
+				"users",
+				"id, name, email, created_at, updated_at",
+				":id, :name, :email, :created_at, :updated_at",
+				"(id) DO UPDATE SET\n\t\tname=EXCLUDED.name, updated_at=EXCLUDED.updated_at",
+				"users.id, users.name, users.email"),
+		},
+		{
+			name: "do nothing",
+			args: args{
+				upsertQuery: NewUpsert("users", user).
+					OnConflict("id").
+					DoNothing().
+					OmitValues("created_at", "updated_at"),
+			},
+
+			wantQueryString: fmt.Sprintf(
+				templUpsert,
+				"users",
+				"id, name, email",
+				":id, :name, :email",
+				"(id) DO NOTHING",
+				"users.id, users.name, users.email, users.created_at, users.updated_at"),
+		},
+		{
+			name: "primary key conflict target",
+			args: args{
+				upsertQuery: NewUpsert("users", user),
+			},
+
+			wantQueryString: fmt.Sprintf(
+				templUpsert,
+				"users",
+				"id, name, email, created_at, updated_at",
+				":id, :name, :email, :created_at, :updated_at",
+				"(id) DO UPDATE SET\n\t\tname=EXCLUDED.name, email=EXCLUDED.email, created_at=EXCLUDED.created_at, updated_at=EXCLUDED.updated_at",
+				"users.id, users.name, users.email, users.created_at, users.updated_at"),
+		},
+		{
+			name: "do nothing without conflict target",
+			args: args{
+				upsertQuery: NewUpsert("users", user).OnConflict().DoNothing(),
+			},
+
+			wantQueryString: fmt.Sprintf(
+				templUpsert,
+				"users",
+				"id, name, email, created_at, updated_at",
+				":id, :name, :email, :created_at, :updated_at",
+				"DO NOTHING",
+				"users.id, users.name, users.email, users.created_at, users.updated_at"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			qString := tt.args.upsertQuery.String()
+			if qString != tt.wantQueryString {
+				t.Errorf("Upsert string = %+v ||  \n want %+v", qString, tt.wantQueryString)
+			}
+			if err := tt.args.upsertQuery.Err(); err != nil {
+				t.Errorf("Upsert error = %v", err)
+			}
+		})
+	}
+
+	// upserts without conflict target columns fail where the dialect needs
+	// them to resolve a conflict
+	for _, d := range []Dialect{Postgres, MySQL, SQLite, SQLServer} {
+		t.Run("no conflict target "+d.Name(), func(t *testing.T) {
+			q := NewUpsert("users", user, UpsertQueryOptions{Dialect: d}).OnConflict()
+
+			wantErr := d.Name() != "mysql"
+			if err := q.Err(); (err != nil) != wantErr {
+				t.Errorf("Upsert error = %v, want error %v", err, wantErr)
+			}
+			if err := q.Compile().Err; (err != nil) != wantErr {
+				t.Errorf("Upsert compiled error = %v, want error %v", err, wantErr)
+			}
+
+			wantErr = d.Name() == "sqlserver"
+			if err := q.DoNothing().Err(); (err != nil) != wantErr {
+				t.Errorf("Upsert do nothing error = %v, want error %v", err, wantErr)
+			}
+		})
+	}
+}
+
+func Test_Dialects(t *testing.T) {
+
+	user := struct {
+		ID   string `db:"id"`
+		Name string `db:"name"`
+	}{}
+
+	for _, d := range []Dialect{Postgres, MySQL, SQLite, SQLServer} {
+		t.Run(d.Name(), func(t *testing.T) {
+
+			queries := []string{
+				NewGet("users", user, GetQueryOptions{Dialect: d}).String(),
+				NewInsert("users", user, InsertQueryOptions{Dialect: d}).String(),
+				NewInsert("users", user, InsertQueryOptions{Dialect: d}).StringMany(2),
+				NewUpdate("users", user, UpdateQueryOptions{Dialect: d}).OmitValues("id").String(),
+				NewDelete("users", user, DeleteQueryOptions{Dialect: d}).String(),
+				NewUpsert("users", user, UpsertQueryOptions{Dialect: d}).
+					OnConflict("id").
+					OmitUpdates("id").
+					String(),
+			}
+
+			golden, err := os.ReadFile(filepath.Join("testdata", d.Name()+".sql"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := strings.Join(queries, "\n\n") + "\n"
+			if got != string(golden) {
+				t.Errorf("%s queries = \n%s\n want \n%s", d.Name(), got, golden)
+			}
+		})
+	}
+}
+
+func Test_Compile(t *testing.T) {
+
+	type user struct {
+		ID    string `db:"id"`
+		Name  string `db:"name"`
+		Email string `db:"email"`
+	}
+
+	tests := []struct {
+		name       string
+		compiled   CompiledQuery
+		arg        interface{}
+		wantSQL    string
+		wantParams []string
+		wantArgs   []interface{}
+	}{
+		{
+			name:       "postgres update",
+			compiled:   NewUpdate("users", user{}).OmitValues("id").OmitReturns("email").Compile(),
+			arg:        user{ID: "1", Name: "a", Email: "a@x.com"},
+			wantSQL:    fmt.Sprintf(templUpdate, "users", "name=$1, email=$2", "id=$3", "users.id, users.name"),
+			wantParams: []string{"name", "email", "id"},
+			wantArgs:   []interface{}{"a", "a@x.com", "1"},
+		},
+		{
+			name:       "mysql select",
+			compiled:   NewGet("users", user{}, GetQueryOptions{Dialect: MySQL}).Where("email=:email AND id=:id").Compile(),
+			arg:        map[string]interface{}{"id": 2, "email": "b@x.com"},
+			wantSQL:    fmt.Sprintf(templSelect, "users.id, users.name, users.email", "users", "email=? AND id=?"),
+			wantParams: []string{"email", "id"},
+			wantArgs:   []interface{}{"b@x.com", 2},
+		},
+		{
+			name:       "casts and quoted strings are kept",
+			compiled:   Compile(`SELECT ':x', "a:b", created_at::date FROM users WHERE id=:id`, Postgres),
+			arg:        &user{ID: "3"},
+			wantSQL:    `SELECT ':x', "a:b", created_at::date FROM users WHERE id=$1`,
+			wantParams: []string{"id"},
+			wantArgs:   []interface{}{"3"},
+		},
+		{
+			name:       "sql server delete",
+			compiled:   NewDelete("users", user{}, DeleteQueryOptions{Dialect: SQLServer}).Compile(),
+			arg:        user{ID: "4"},
+			wantSQL:    fmt.Sprintf(templDelete, "users", "id=@p1"),
+			wantParams: []string{"id"},
+			wantArgs:   []interface{}{"4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			if tt.compiled.SQL != tt.wantSQL {
+				t.Errorf("Compile SQL = %+v ||  \n want %+v", tt.compiled.SQL, tt.wantSQL)
+			}
+			if fmt.Sprint(tt.compiled.Params) != fmt.Sprint(tt.wantParams) {
+				t.Errorf("Compile params = %+v, want %+v", tt.compiled.Params, tt.wantParams)
+			}
+
+			args, err := tt.compiled.Bind(tt.arg)
+			if err != nil {
+				t.Fatalf("Bind error = %v", err)
+			}
+			if fmt.Sprint(args) != fmt.Sprint(tt.wantArgs) {
+				t.Errorf("Bind args = %+v, want %+v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	if _, err := Compile("id=:id", Postgres).Bind(map[string]interface{}{}); err == nil {
+		t.Errorf("Bind expected an error for a missing parameter")
+	}
+}
+
+func Test_Quoting(t *testing.T) {
+
+	order := struct {
+		ID        string `db:"id"`
+		Order     int    `db:"order"`
+		User      string `db:"user"`
+		CreatedBy string `db:"createdBy"`
+	}{}
+
+	tests := []struct {
+		name            string
+		query           fmt.Stringer
+		wantQueryString string
+	}{
+		{
+			name:  "get quotes reserved and mixed case columns",
+			query: NewGet("orders", order),
+			wantQueryString: fmt.Sprintf(
+				templSelect,
+				`orders.id, orders."order", orders."user", orders."createdBy"`,
+				"orders",
+				"id=:id",
+			),
+		},
+		{
+			name:  "insert quotes reserved table",
+			query: NewInsert("user", order, InsertQueryOptions{Dialect: MySQL}).OmitValues("id"),
+			wantQueryString: fmt.Sprintf(
+				templInsertNoReturn,
+				"`user`",
+				"`order`, `user`, `createdBy`",
+				":order, :user, :createdBy",
+			),
+		},
+		{
+			name:  "update always quotes",
+			query: NewUpdate("orders", order, UpdateQueryOptions{Dialect: SQLServer, Quote: QuoteAlways}).OmitValues("id").OmitReturns("user", "createdBy"),
+			wantQueryString: fmt.Sprintf(
+				templUpdateOutput,
+				"[orders]",
+				"[order]=:order, [user]=:user, [createdBy]=:createdBy",
+				"INSERTED.[id], INSERTED.[order]",
+				"[id]=:id",
+			),
+		},
+		{
+			name:  "never quotes",
+			query: NewGet("orders", order, GetQueryOptions{Quote: QuoteNever}).OmitReturns("id"),
+			wantQueryString: fmt.Sprintf(
+				templSelect,
+				"orders.order, orders.user, orders.createdBy",
+				"orders",
+				"id=:id",
+			),
+		},
+		{
+			name:  "upsert quotes conflict and update columns",
+			query: NewUpsert("orders", order).OnConflict("order").DoUpdate("user").OmitValues("createdBy").OmitReturns("createdBy"),
+			wantQueryString: fmt.Sprintf(
+				templUpsert,
+				"orders",
+				`id, "order", "user"`,
+				":id, :order, :user",
+				"(\"order\") DO UPDATE SET\n\t\t\"user\"=EXCLUDED.\"user\"",
+				`orders.id, orders."order", orders."user"`,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			qString := tt.query.String()
+			if qString != tt.wantQueryString {
+				t.Errorf("Quoted string = %+v ||  \n want %+v", qString, tt.wantQueryString)
+			}
+		})
+	}
+}
+
+func Test_TagOptions(t *testing.T) {
+
+	user := struct {
+		ID        string `db:"id,pk"`
+		Name      string `db:"name,omitempty"`
+		Password  string `db:"password,return=-"`
+		CreatedAt string `db:"created_at,readonly"`
+		UpdatedAt string `db:"updated_at,insert=-"`
+		Version   int    `db:"version,update=-"`
+		Ignored   string `db:"-"`
+	}{}
+
+	tests := []struct {
+		name            string
+		query           fmt.Stringer
+		wantQueryString string
+	}{
+		{
+			name:  "get omits non returned columns",
+			query: NewGet("users", user),
+			wantQueryString: fmt.Sprintf(
+				templSelect,
+				"users.id, users.name, users.created_at, users.updated_at, users.version",
+				"users",
+				"id=:id",
+			),
+		},
+		{
+			name:  "insert omits read only and non inserted columns",
+			query: NewInsert("users", user),
+			wantQueryString: fmt.Sprintf(
+				templInsert,
+				"users",
+				"id, name, password, version",
+				":id, :name, :password, :version",
+				"users.id, users.name, users.created_at, users.updated_at, users.version",
+			),
+		},
+		{
+			name:  "update omits primary key, read only and non updated columns",
+			query: NewUpdate("users", user),
+			wantQueryString: fmt.Sprintf(
+				templUpdate,
+				"users",
+				"name=:name, password=:password, updated_at=:updated_at",
+				"id=:id",
+				"users.id, users.name, users.created_at, users.updated_at, users.version",
+			),
+		},
+		{
+			name:  "upsert updates only inserted updatable columns",
+			query: NewUpsert("users", user).OnConflict("id").OmitReturns("created_at", "updated_at", "version"),
+			wantQueryString: fmt.Sprintf(
+				templUpsert,
+				"users",
+				"id, name, password, version",
+				":id, :name, :password, :version",
+				"(id) DO UPDATE SET\n\t\tname=EXCLUDED.name, password=EXCLUDED.password",
+				"users.id, users.name",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			qString := tt.query.String()
+			if qString != tt.wantQueryString {
+				t.Errorf("Query string = %+v ||  \n want %+v", qString, tt.wantQueryString)
+			}
+		})
+	}
+}
+
+func Test_NestedStructs(t *testing.T) {
+
+	type Timestamps struct {
+		CreatedAt string `db:"created_at,readonly"`
+		UpdatedAt string `db:"updated_at"`
+	}
+
+	type Address struct {
+		Street string `db:"street"`
+		City   string `db:"city"`
+	}
+
+	type user struct {
+		ID string `db:"id"`
+		*Timestamps
+		Address Address `db:"address_"`
+	}
+
+	q := NewInsert("users", &user{})
+
+	wantQueryString := fmt.Sprintf(
+		templInsert,
+		"users",
+		"id, updated_at, address_street, address_city",
+		":id, :updated_at, :address_street, :address_city",
+		"users.id, users.created_at, users.updated_at, users.address_street, users.address_city",
+	)
+	if qString := q.String(); qString != wantQueryString {
+		t.Errorf("Insert string = %+v ||  \n want %+v", qString, wantQueryString)
+	}
+
+	args, err := q.Compile().Bind(user{ID: "1", Address: Address{City: "Paris"}})
+	if err != nil {
+		t.Fatalf("Bind error = %v", err)
+	}
+	if fmt.Sprint(args) != fmt.Sprint([]interface{}{"1", nil, "", "Paris"}) {
+		t.Errorf("Bind args = %+v", args)
+	}
+
+	if err := NewGet("users", "not a struct").Err(); err == nil {
+		t.Errorf("NewGet expected an error for a non struct value")
+	}
+	if err := NewUpdate("users", 1).Fn()(nil, nil); err == nil {
+		t.Errorf("Fn expected an error for a non struct value")
+	}
+}
+
+func Test_PrimaryKey(t *testing.T) {
+
+	membership := struct {
+		TenantID string `db:"tenant_id,pk"`
+		ID       string `db:"id,pk"`
+		Role     string `db:"role"`
+	}{}
+
+	account := struct {
+		UUID  string `db:"uuid"`
+		Email string `db:"email"`
+	}{}
+
+	grant := struct {
+		TenantID string `db:"tenant_id"`
+		ID       string `db:"id"`
+		Scope    string `db:"scope"`
+	}{}
+
+	tests := []struct {
+		name            string
+		query           fmt.Stringer
+		wantQueryString string
+	}{
+		{
+			name:  "get composite key",
+			query: NewGet("memberships", membership),
+			wantQueryString: fmt.Sprintf(
+				templSelect,
+				"memberships.tenant_id, memberships.id, memberships.role",
+				"memberships",
+				"tenant_id=:tenant_id AND id=:id",
+			),
+		},
+		{
+			name:  "update composite key",
+			query: NewUpdate("memberships", membership),
+			wantQueryString: fmt.Sprintf(
+				templUpdate,
+				"memberships",
+				"role=:role",
+				"tenant_id=:tenant_id AND id=:id",
+				"memberships.tenant_id, memberships.id, memberships.role",
+			),
+		},
+		{
+			name:  "delete composite key",
+			query: NewDelete("memberships", membership),
+			wantQueryString: fmt.Sprintf(
+				templDelete,
+				"memberships",
+				"tenant_id=:tenant_id AND id=:id",
+			),
+		},
+		{
+			name:  "primary key method",
+			query: NewDelete("accounts", account).PrimaryKey("uuid"),
+			wantQueryString: fmt.Sprintf(
+				templDelete,
+				"accounts",
+				"uuid=:uuid",
+			),
+		},
+		{
+			name:  "update primary key method",
+			query: NewUpdate("grants", grant).PrimaryKey("tenant_id", "id").OmitReturns("tenant_id", "id", "scope"),
+			wantQueryString: fmt.Sprintf(
+				templUpdateNoReturn,
+				"grants",
+				"scope=:scope",
+				"tenant_id=:tenant_id AND id=:id",
+			),
+		},
+		{
+			name:  "where overrides primary key",
+			query: NewGet("accounts", account).PrimaryKey("uuid").Where("email=:email"),
+			wantQueryString: fmt.Sprintf(
+				templSelect,
+				"accounts.uuid, accounts.email",
+				"accounts",
+				"email=:email",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			qString := tt.query.String()
+			if qString != tt.wantQueryString {
+				t.Errorf("Query string = %+v ||  \n want %+v", qString, tt.wantQueryString)
+			}
+		})
+	}
+
+	// the default where clause of a struct without pk or id columns fails
+	errs := []error{
+		NewGet("accounts", account).Err(),
+		NewUpdate("accounts", account).Err(),
+		NewDelete("accounts", account).Err(),
+		NewDelete("accounts", account).Compile().Err,
+	}
+	for i, err := range errs {
+		if err == nil {
+			t.Errorf("default where clause without primary key %d did not fail", i)
+		}
+	}
+	if got := NewDelete("accounts", account).String(); got != fmt.Sprintf(templDelete, "accounts", invalidWhereClause) {
+		t.Errorf("Query string = %+v ||  \n want %+v", got, fmt.Sprintf(templDelete, "accounts", invalidWhereClause))
+	}
+
+	valid := []error{
+		NewGet("accounts", account).All().Err(),
+		NewGet("accounts", account).Where(Eq("email")).Err(),
+		NewUpdate("accounts", account).PrimaryKey("uuid").Err(),
+		NewDelete("accounts", nil).Err(),
+	}
+	for i, err := range valid {
+		if err != nil {
+			t.Errorf("query %d without default where clause failed: %v", i, err)
+		}
+	}
+}
+
+type contextRecorder struct {
+	ctx     context.Context
+	queries []string
+}
+
+func (r *contextRecorder) record(ctx context.Context, q string) {
+	r.ctx = ctx
+	r.queries = append(r.queries, q)
+}
+
+func (r *contextRecorder) SelectContext(ctx context.Context, q string, dest interface{}, args ...interface{}) error {
+	r.record(ctx, q)
+	return nil
+}
+
+func (r *contextRecorder) InsertContext(ctx context.Context, q string, val interface{}) error {
+	r.record(ctx, q)
+	return nil
+}
+
+func (r *contextRecorder) DeleteContext(ctx context.Context, q string, args ...interface{}) (int64, error) {
+	r.record(ctx, q)
+	return 1, nil
+}
+
+func (r *contextRecorder) QueryPositionalContext(ctx context.Context, q string, dest interface{}, args ...interface{}) error {
+	return r.SelectContext(ctx, q, dest, args...)
+}
+
+func (r *contextRecorder) ExecPositionalContext(ctx context.Context, q string, args ...interface{}) (int64, error) {
+	return r.DeleteContext(ctx, q, args...)
+}
+
+func Test_FnContext(t *testing.T) {
+
+	type ctxKey struct{}
+
+	user := struct {
+		ID   string `db:"id"`
+		Name string `db:"name"`
+	}{}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	tx := &contextRecorder{}
+
+	get := NewGet("users", user)
+	insert := NewInsert("users", user)
+	del := NewDelete("users", user)
+
+	if err := get.FnSelectContext()(ctx, tx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := insert.FnContext()(ctx, tx, user); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := del.FnContext()(ctx, tx, "1"); err != nil || n != 1 {
+		t.Fatalf("FnContext delete = %d, %v", n, err)
+	}
+
+	if tx.ctx.Value(ctxKey{}) != "request" {
+		t.Errorf("FnContext did not pass the context to the querier")
+	}
+	want := []string{get.String(), insert.String(), del.String()}
+	if fmt.Sprint(tx.queries) != fmt.Sprint(want) {
+		t.Errorf("FnContext queries = %+v, want %+v", tx.queries, want)
+	}
+}
+
+type typedRecorder struct {
+	contextRecorder
+	name string
+}
+
+func (r *typedRecorder) SelectContext(ctx context.Context, q string, dest interface{}, args ...interface{}) error {
+	r.record(ctx, q)
+	rows := dest.(*[]genericUser)
+	*rows = append(*rows, genericUser{ID: "1", Name: r.name})
+	return nil
+}
+
+func (r *typedRecorder) UpdateContext(ctx context.Context, q string, val interface{}) error {
+	r.record(ctx, q)
+	val.(*genericUser).Name = r.name
+	return nil
+}
+
+type genericUser struct {
+	ID   string `db:"id"`
+	Name string `db:"name"`
+}
+
+func Test_Generics(t *testing.T) {
+
+	get := NewGetT[genericUser]("users").Where("name=:name")
+	if get.String() != NewGet("users", genericUser{}).Where("name=:name").String() {
+		t.Errorf("NewGetT string = %+v", get.String())
+	}
+
+	insert := NewInsertT[*genericUser]("users").OmitValues("id")
+	if insert.String() != NewInsert("users", genericUser{}).OmitValues("id").String() {
+		t.Errorf("NewInsertT string = %+v", insert.String())
+	}
+
+	tx := &typedRecorder{name: "typed"}
+
+	users, err := get.FnSelect()(context.Background(), tx, "typed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Name != "typed" {
+		t.Errorf("FnSelect rows = %+v", users)
+	}
+
+	user := genericUser{ID: "1"}
+	if err := NewUpdateT[genericUser]("users").Fn()(context.Background(), tx, &user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "typed" {
+		t.Errorf("Fn row = %+v", user)
+	}
+
+	if err := NewGetT[interface{}]("users").Err(); err == nil {
+		t.Errorf("NewGetT expected an error for a non struct type")
+	}
+}
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sql state " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+// MySQLError mirrors the go-sql-driver error, matched by name and Number
+type MySQLError struct {
+	Number  uint16
+	Message string
+}
+
+func (e *MySQLError) Error() string { return e.Message }
+
+type mssqlError int32
+
+func (e mssqlError) Error() string         { return "mssql error" }
+func (e mssqlError) SQLErrorNumber() int32 { return int32(e) }
+
+type txRecorder struct {
+	Tx
+	begun, commits, rollbacks int
+	opts                      TxOptions
+}
+
+func (r *txRecorder) BeginTx(ctx context.Context, opts TxOptions) (TxCommitter, error) {
+	r.begun++
+	r.opts = opts
+	return r, nil
+}
+
+func (r *txRecorder) Commit(ctx context.Context) error {
+	r.commits++
+	return nil
+}
+
+func (r *txRecorder) Rollback(ctx context.Context) error {
+	r.rollbacks++
+	return nil
+}
+
+func Test_WithTx(t *testing.T) {
+
+	ctx := context.Background()
+	noBackoff := func(int) time.Duration { return 0 }
+
+	db := &txRecorder{}
+	attempts := 0
+	err := WithTx(ctx, db, func(tx Tx) error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("insert: %w", sqlStateError("40001"))
+		}
+		return nil
+	}, TxOptions{Isolation: sql.LevelSerializable, Backoff: noBackoff})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.begun != 3 || db.rollbacks != 2 || db.commits != 1 {
+		t.Errorf("retried tx begun %d, rolled back %d, committed %d", db.begun, db.rollbacks, db.commits)
+	}
+	if db.opts.Isolation != sql.LevelSerializable {
+		t.Errorf("tx isolation = %v", db.opts.Isolation)
+	}
+
+	db = &txRecorder{}
+	wantErr := errors.New("not retryable")
+	if err := WithTx(ctx, db, func(tx Tx) error { return wantErr }); err != wantErr {
+		t.Errorf("WithTx error = %v, want %v", err, wantErr)
+	}
+	if db.begun != 1 || db.rollbacks != 1 {
+		t.Errorf("failed tx begun %d, rolled back %d", db.begun, db.rollbacks)
+	}
+
+	db = &txRecorder{}
+	err = WithTx(ctx, db, func(tx Tx) error {
+		return sqlStateError("40P01")
+	}, TxOptions{MaxAttempts: 2, Backoff: noBackoff})
+	if !IsRetryable(err) || db.begun != 2 {
+		t.Errorf("deadlocked tx error = %v, begun %d", err, db.begun)
+	}
+
+	retryable := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("update: %w", &MySQLError{Number: 1213}), true},
+		{&MySQLError{Number: 1205}, true},
+		{&MySQLError{Number: 1062}, false},
+		{fmt.Errorf("update: %w", mssqlError(1205)), true},
+		{mssqlError(2627), false},
+		{sqlStateError("23505"), false},
+	}
+	for _, tc := range retryable {
+		if got := IsRetryable(tc.err); got != tc.want {
+			t.Errorf("IsRetryable(%v) = %v ||  \n want %v", tc.err, got, tc.want)
+		}
+	}
+
+	db = &txRecorder{}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("WithTx did not re-panic")
+			}
+		}()
+		_ = WithTx(ctx, db, func(tx Tx) error { panic("boom") })
+	}()
+	if db.rollbacks != 1 || db.commits != 0 {
+		t.Errorf("panicking tx rolled back %d, committed %d", db.rollbacks, db.commits)
+	}
+}
+
+func Test_Middleware(t *testing.T) {
+
+	type ctxKey struct{}
+
+	user := struct {
+		ID   string `db:"id"`
+		Name string `db:"name"`
+	}{}
+
+	var calls []string
+	record := func(prefix string) Middleware {
+		return func(next Exec) Exec {
+			return func(ctx context.Context, info QueryInfo) (Result, error) {
+				res, err := next(ctx, info)
+				calls = append(calls, fmt.Sprintf("%s %s %s %s %d", prefix, info.Name, info.Table, info.Operation, res.Rows))
+				return res, err
+			}
+		}
+	}
+
+	Use(record("global"))
+	t.Cleanup(func() { global.middleware = nil })
+
+	var after QueryInfo
+	var afterCtx context.Context
+	hooks := Hooks{
+		Before: func(ctx context.Context, info QueryInfo) context.Context {
+			return context.WithValue(ctx, ctxKey{}, "hooked")
+		},
+		After: func(ctx context.Context, info QueryInfo, res Result, err error, elapsed time.Duration) {
+			after, afterCtx = info, ctx
+		},
+	}
+
+	tx := &contextRecorder{}
+	ctx := context.Background()
+
+	get := NewGet("users", user).Named("ListUsers").Use(record("query"), hooks.Middleware())
+	del := NewDelete("users", user).Named("DeleteUser")
+
+	var users []struct{}
+	if err := get.FnSelectContext()(ctx, tx, &users, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if tx.ctx.Value(ctxKey{}) != "hooked" || afterCtx.Value(ctxKey{}) != "hooked" {
+		t.Errorf("hooks did not pass the Before context to the querier and After")
+	}
+	if _, err := del.FnContext()(ctx, tx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := get.Compile().FnQueryContext()(ctx, tx, &users, map[string]interface{}{"id": "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := del.Compile().FnExecContext()(ctx, tx, map[string]interface{}{"id": "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Compile("DELETE FROM sessions", Postgres).FnExecContext()(ctx, tx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"query ListUsers users select 0",
+		"global ListUsers users select 0",
+		"global DeleteUser users delete 1",
+		"query ListUsers users select 0",
+		"global ListUsers users select 0",
+		"global DeleteUser users delete 1",
+		"global   exec 1",
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("middleware calls = %+v ||  \n want %+v", calls, want)
+	}
+	if after.SQL != get.Compile().SQL || fmt.Sprint(after.Args) != "[a]" {
+		t.Errorf("hook query info = %+v", after)
+	}
+}
+
+func Test_Registry(t *testing.T) {
+
+	user := struct {
+		ID   string `db:"id"`
+		Name string `db:"name"`
+	}{}
+
+	r := NewRegistry()
+	get := Must(r.RegisterGetOne("GetUser", NewGet("users", user)))
+	Must(r.RegisterDelete("DeleteUser", NewDelete("users", user)))
+
+	if get.name != "GetUser" {
+		t.Errorf("registered query name = %q, want GetUser", get.name)
+	}
+
+	want := []RegisteredQuery{
+		{Name: "DeleteUser", Table: "users", Operation: OpDelete, Cardinality: CardinalityExecRows, SQL: fmt.Sprintf(templDelete, "users", "id=:id")},
+		{Name: "GetUser", Table: "users", Operation: OpSelectOne, Cardinality: CardinalityOne, SQL: get.String()},
+	}
+	if got := r.Queries(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Queries = %+v ||  \n want %+v", got, want)
+	}
+
+	if _, err := r.RegisterGet("GetUser", NewGet("users", user)); err == nil {
+		t.Errorf("registering a query twice did not fail")
+	}
+	if _, err := r.RegisterDelete("DeleteByName", NewDelete("users", user).Where(Eq("nmae"))); err == nil {
+		t.Errorf("registering an invalid query did not fail")
+	}
+	if got := len(r.Queries()); got != 2 {
+		t.Errorf("Queries = %d queries after failed registrations, want 2", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Must of a failed registration did not panic")
+		}
+	}()
+	Must(r.RegisterGet("GetUser", NewGet("users", user)))
+}
+
+func Test_CreateTable(t *testing.T) {
+
+	type account struct {
+		ID        int64           `db:"id,pk"`
+		Email     string          `db:"email,unique"`
+		Nickname  *string         `db:"nickname"`
+		Balance   float64         `db:"balance,type=numeric(10,2),default=0"`
+		Status    string          `db:"status,default='active'"`
+		Referrer  sql.NullInt64   `db:"referrer"`
+		Settings  json.RawMessage `db:"settings,notnull"`
+		CreatedAt time.Time       `db:"created_at,readonly,default=now()"`
+		Age       *int16          `db:"age"`
+	}
+
+	tests := []struct {
+		name            string
+		query           fmt.Stringer
+		wantQueryString string
+	}{
+		{
+			name:  "postgres",
+			query: NewCreateTable("accounts", account{}),
+			wantQueryString: fmt.Sprintf(
+				templCreateTable,
+				"",
+				"accounts",
+				strings.Join([]string{
+					"id bigint NOT NULL",
+					"email text NOT NULL UNIQUE",
+					"nickname text",
+					"balance numeric(10,2) NOT NULL DEFAULT 0",
+					"status text NOT NULL DEFAULT 'active'",
+					"referrer bigint",
+					"settings jsonb NOT NULL",
+					"created_at timestamptz NOT NULL DEFAULT now()",
+					"age smallint",
+					"PRIMARY KEY (id)",
+				}, ",\n\t\t"),
+			),
+		},
+		{
+			name: "sqlserver if not exists",
+			query: NewCreateTable("user", struct {
+				ID   string `db:"id"`
+				Name string `db:"name"`
+			}{}, CreateTableQueryOptions{Dialect: SQLServer}).IfNotExists(),
+			wantQueryString: fmt.Sprintf(
+				templCreateTableIfNotExistsSQLServer,
+				"[user]",
+				"[user]",
+				"id nvarchar(255) NOT NULL,\n\t\tname nvarchar(255) NOT NULL,\n\t\tPRIMARY KEY (id)",
+			),
+		},
+		{
+			name: "mysql if not exists without primary key",
+			query: NewCreateTable("events", struct {
+				At   time.Time `db:"at"`
+				Data []byte    `db:"data"`
+			}{}, CreateTableQueryOptions{Dialect: MySQL}).IfNotExists(),
+			wantQueryString: fmt.Sprintf(
+				templCreateTable,
+				"IF NOT EXISTS ",
+				"events",
+				"at datetime(6) NOT NULL,\n\t\tdata blob NOT NULL",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.wantQueryString {
+				t.Errorf("CreateTableQuery.String() string = %+v ||  \n want %+v", got, tt.wantQueryString)
+			}
+		})
+	}
+
+	q := NewCreateTable("accounts", account{}, CreateTableQueryOptions{Dialect: SQLServer})
+	if got := q.DropIfExists(); got != "DROP TABLE IF EXISTS accounts" {
+		t.Errorf("DropIfExists string = %+v", got)
+	}
+
+	q = NewCreateTable("points", struct {
+		Location [2]float64 `db:"location"`
+	}{})
+	if q.Err() == nil {
+		t.Errorf("NewCreateTable did not fail for a field without a column type")
+	}
+}
+
+func Test_Predicates(t *testing.T) {
+
+	user := struct {
+		ID        string `db:"id"`
+		Email     string `db:"email"`
+		Status    string `db:"status"`
+		Age       int    `db:"age"`
+		DeletedAt string `db:"deleted_at"`
+		Order     int    `db:"order"`
+	}{}
+
+	tests := []struct {
+		name            string
+		query           fmt.Stringer
+		wantQueryString string
+	}{
+		{
+			name:  "comparison",
+			query: NewGet("users", user).Where(Eq("email")),
+			wantQueryString: fmt.Sprintf(
+				templSelect,
+				`users.id, users.email, users.status, users.age, users.deleted_at, users."order"`,
+				"users",
+				"email=:email",
+			),
+		},
+		{
+			name: "junctions",
+			query: NewDelete("users", user).Where(And(
+				Or(Eq("status"), In("status", "statuses")),
+				Gte("age", "min_age"),
+				Lt("age", "max_age"),
+				Not(IsNull("deleted_at")),
+				Raw("1=1"),
+			)),
+			wantQueryString: fmt.Sprintf(
+				templDelete,
+				"users",
+				"(status=:status OR status = ANY(:statuses)) AND age>=:min_age AND age<:max_age AND NOT (deleted_at IS NULL) AND (1=1)",
+			),
+		},
+		{
+			name: "quoted column",
+			query: NewUpdate("users", user).
+				OmitValues("id", "email", "status", "age", "deleted_at").
+				OmitReturns("id", "email", "status", "age", "deleted_at", "order").
+				Where(And(Like("email"), Ne("order"))),
+			wantQueryString: fmt.Sprintf(
+				templUpdateNoReturn,
+				"users",
+				`"order"=:order`,
+				`email LIKE :email AND "order"<>:order`,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.wantQueryString {
+				t.Errorf("Where string = %+v ||  \n want %+v", got, tt.wantQueryString)
+			}
+		})
+	}
+
+	errs := []error{
+		NewGet("users", user).Where(Eq("emial")).Err(),
+		NewGet("users", user).Where(Eq("email", "e-mail")).Err(),
+		NewGet("users", user).Where(Or()).Err(),
+		NewGet("users", user).Where(42).Err(),
+	}
+	for i, err := range errs {
+		if err == nil {
+			t.Errorf("invalid where clause %d did not fail", i)
+		}
+	}
+}
+
+func Test_InvalidPredicate(t *testing.T) {
+
+	user := struct {
+		ID    string `db:"id"`
+		Email string `db:"email"`
+		Order int    `db:"order"`
+	}{}
+
+	tests := []struct {
+		name            string
+		query           fmt.Stringer
+		err             error
+		compiled        CompiledQuery
+		wantQueryString string
+	}{
+		{
+			name:            "get",
+			query:           NewGet("users", user).All().Where(Eq("emial")),
+			err:             NewGet("users", user).All().Where(Eq("emial")).Err(),
+			compiled:        NewGet("users", user).All().Where(Eq("emial")).Compile(),
+			wantQueryString: fmt.Sprintf(templSelect, `users.id, users.email, users."order"`, "users", invalidWhereClause),
+		},
+		{
+			name:            "delete",
+			query:           NewDelete("users", user).Where(Eq("emial")),
+			err:             NewDelete("users", user).Where(Eq("emial")).Err(),
+			compiled:        NewDelete("users", user).Where(Eq("emial")).Compile(),
+			wantQueryString: fmt.Sprintf(templDelete, "users", invalidWhereClause),
+		},
+		{
+			name:            "update",
+			query:           NewUpdate("users", user).OmitValues("id", "email").OmitReturns("id", "email", "order").Where(Not(Eq("emial"))),
+			err:             NewUpdate("users", user).Where(Not(Eq("emial"))).Err(),
+			compiled:        NewUpdate("users", user).Where(Not(Eq("emial"))).Compile(),
+			wantQueryString: fmt.Sprintf(templUpdateNoReturn, "users", `"order"=:order`, invalidWhereClause),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.wantQueryString {
+				t.Errorf("Where string = %+v ||  \n want %+v", got, tt.wantQueryString)
+			}
+			if tt.err == nil {
+				t.Errorf("invalid predicate did not fail")
+			}
+			if tt.compiled.Err == nil {
+				t.Errorf("compiled query of an invalid predicate has no error")
+			}
+			if _, err := tt.compiled.BindArgs(map[string]interface{}{"id": "1"}); err == nil {
+				t.Errorf("compiled query of an invalid predicate bound its arguments")
+			}
+		})
+	}
+}
+
+type argsRecorder struct {
+	queries []string
+	args    [][]interface{}
+}
+
+func (r *argsRecorder) Select(q string, dest interface{}, args ...interface{}) error {
+	r.queries = append(r.queries, q)
+	r.args = append(r.args, args)
+	return nil
+}
+
+func (r *argsRecorder) QueryPositional(q string, dest interface{}, args ...interface{}) error {
+	return r.Select(q, dest, args...)
+}
+
+// arrayValuer stands in for a driver array type such as pq.Array
+type arrayValuer []string
+
+func (a arrayValuer) Value() (driver.Value, error) { return "{" + strings.Join(a, ",") + "}", nil }
+
+func Test_InExpansion(t *testing.T) {
+
+	user := struct {
+		ID     string `db:"id"`
+		Status string `db:"status"`
+	}{}
+
+	tx := &argsRecorder{}
+	get := NewGet("users", user, GetQueryOptions{Dialect: MySQL}).
+		Where(And(In("id", "ids"), Eq("status")))
+	selectUsers := get.FnSelect()
+
+	calls := [][]interface{}{
+		{[]string{"1", "2", "3"}, "active"},
+		{map[string]interface{}{"ids": []int{4}, "status": "active"}},
+		{[]string{}, "active"},
+		{[]string{"5", "6", "7"}, "banned"},
+	}
+	for _, args := range calls {
+		if err := selectUsers(tx, nil, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wantQueries := []string{
+		fmt.Sprintf(templSelect, "users.id, users.status", "users", "id IN (:ids_0, :ids_1, :ids_2) AND status=:status"),
+		fmt.Sprintf(templSelect, "users.id, users.status", "users", "id IN (:ids_0) AND status=:status"),
+		fmt.Sprintf(templSelect, "users.id, users.status", "users", "1=0 AND status=:status"),
+		fmt.Sprintf(templSelect, "users.id, users.status", "users", "id IN (:ids_0, :ids_1, :ids_2) AND status=:status"),
+	}
+	if fmt.Sprint(tx.queries) != fmt.Sprint(wantQueries) {
+		t.Errorf("expanded queries = %+v ||  \n want %+v", tx.queries, wantQueries)
+	}
+
+	wantArgs := fmt.Sprint([]interface{}{map[string]interface{}{"ids_0": "5", "ids_1": "6", "ids_2": "7", "status": "banned"}})
+	if got := fmt.Sprint(tx.args[3]); got != wantArgs {
+		t.Errorf("expanded args = %s, want %s", got, wantArgs)
+	}
+
+	mysql := GetQueryOptions{Dialect: MySQL}
+	tests := []struct {
+		name      string
+		query     GetQuery
+		args      []interface{}
+		wantQuery string
+		// wantArgs the arguments passed to the querier, if checked
+		wantArgs []interface{}
+		wantErr  bool
+	}{
+		{
+			name:      "not in",
+			query:     NewGet("users", user, mysql).Where(Not(In("id", "ids"))),
+			args:      []interface{}{[]string{"1"}},
+			wantQuery: "NOT (id IN (:ids_0))",
+		},
+		{
+			name:      "not in empty",
+			query:     NewGet("users", user, mysql).Where(Not(In("id", "ids"))),
+			args:      []interface{}{[]string{}},
+			wantQuery: "NOT (1=0)",
+		},
+		{
+			name:      "raw in empty",
+			query:     NewGet("users", user, mysql).Where("COALESCE(name, status) IN (:ids)"),
+			args:      []interface{}{[]string{}},
+			wantQuery: "COALESCE(name, status) IN (NULL)",
+		},
+		{
+			name:    "raw not in empty",
+			query:   NewGet("users", user, mysql).Where("(id NOT IN (:ids))"),
+			args:    []interface{}{[]string{}},
+			wantErr: true,
+		},
+		{
+			name:      "quoted in",
+			query:     NewGet("users", user, mysql).Where("status = 'x IN (:y)'"),
+			wantQuery: "status = 'x IN (:y)'",
+		},
+		{
+			name:      "postgres any",
+			query:     NewGet("users", user).Where(Not(In("id", "ids"))),
+			args:      []interface{}{arrayValuer{"1"}},
+			wantQuery: "NOT (id = ANY(:ids))",
+			wantArgs:  []interface{}{arrayValuer{"1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &argsRecorder{}
+			err := tt.query.FnSelect()(tx, nil, tt.args...)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got query %v", tx.queries)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := fmt.Sprintf(templSelect, "users.id, users.status", "users", tt.wantQuery)
+			if tx.queries[0] != want {
+				t.Errorf("expanded query string = %+v ||  \n want %+v", tx.queries[0], want)
+			}
+			if tt.wantArgs != nil && fmt.Sprint(tx.args[0]) != fmt.Sprint(tt.wantArgs) {
+				t.Errorf("args = %v, want %v", tx.args[0], tt.wantArgs)
+			}
+		})
+	}
+
+	compiled := NewGet("users", user, mysql).Where(In("id", "ids")).Compile()
+	if _, err := compiled.BindArgs([]string{"1", "2"}); err == nil {
+		t.Errorf("compiled In query bound a slice to a single parameter")
+	}
+
+	tx = &argsRecorder{}
+	if err := compiled.FnQuery()(tx, nil, map[string]interface{}{"ids": []string{"1", "2"}}); err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf(templSelect, "users.id, users.status", "users", "id IN (?, ?)")
+	if tx.queries[0] != want || fmt.Sprint(tx.args[0]) != "[1 2]" {
+		t.Errorf("compiled expanded query = %+v %v ||  \n want %+v [1 2]", tx.queries[0], tx.args[0], want)
+	}
+}
+
+func Test_OrderLimitOffset(t *testing.T) {
+
+	user := struct {
+		ID        string `db:"id"`
+		Name      string `db:"name"`
+		CreatedAt string `db:"created_at"`
+	}{}
+
+	selects := "users.id, users.name, users.created_at"
+
+	tests := []struct {
+		name            string
+		query           fmt.Stringer
+		wantQueryString string
+	}{
+		{
+			name:            "postgres",
+			query:           NewGet("users", user).All().OrderBy("created_at", Desc).OrderBy("id", Asc).Limit(10).Offset(20),
+			wantQueryString: fmt.Sprintf(templSelectAll, selects, "users") + " ORDER BY created_at DESC, id ASC LIMIT 10 OFFSET 20",
+		},
+		{
+			name:            "limit param",
+			query:           NewGet("users", user).Where(Eq("name")).LimitParam(":limit"),
+			wantQueryString: fmt.Sprintf(templSelect, selects, "users", "name=:name") + " LIMIT :limit",
+		},
+		{
+			name:            "mysql offset without limit",
+			query:           NewGet("users", user, GetQueryOptions{Dialect: MySQL}).All().Offset(5),
+			wantQueryString: fmt.Sprintf(templSelectAll, selects, "users") + " LIMIT 18446744073709551615 OFFSET 5",
+		},
+		{
+			name:            "sqlserver",
+			query:           NewGet("users", user, GetQueryOptions{Dialect: SQLServer}).All().OrderBy("name", Asc).Limit(10).OffsetParam(":offset"),
+			wantQueryString: fmt.Sprintf(templSelectAll, selects, "users") + " ORDER BY name ASC OFFSET :offset ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			name:            "sqlserver without order",
+			query:           NewGet("users", user, GetQueryOptions{Dialect: SQLServer}).All().Limit(1),
+			wantQueryString: fmt.Sprintf(templSelectAll, selects, "users") + " ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 1 ROWS ONLY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.wantQueryString {
+				t.Errorf("GetQuery.String() string = %+v ||  \n want %+v", got, tt.wantQueryString)
+			}
+		})
+	}
+
+	errs := []error{
+		NewGet("users", user).OrderBy("nmae", Asc).Err(),
+		NewGet("users", user).OrderBy("name", "sideways").Err(),
+		NewGet("users", user).Limit(-1).Err(),
+		NewGet("users", user).LimitParam("limit").Err(),
+	}
+	for i, err := range errs {
+		if err == nil {
+			t.Errorf("invalid ordering %d did not fail", i)
+		}
+	}
+}
+
+func Test_Keyset(t *testing.T) {
+
+	user := struct {
+		ID        string `db:"id"`
+		Name      string `db:"name"`
+		CreatedAt string `db:"created_at"`
+	}{}
+
+	selects := "users.id, users.name, users.created_at"
+
+	tests := []struct {
+		name            string
+		query           fmt.Stringer
+		wantQueryString string
+	}{
+		{
+			name:            "first page",
+			query:           NewGet("users", user).Keyset(Asc, "created_at", "id").Limit(10),
+			wantQueryString: fmt.Sprintf(templSelectAll, selects, "users") + " ORDER BY created_at ASC, id ASC LIMIT 10",
+		},
+		{
+			name:            "after",
+			query:           NewGet("users", user).Keyset(Asc, "created_at", "id").Limit(10).After(),
+			wantQueryString: fmt.Sprintf(templSelect, selects, "users", "(created_at, id) > (:after_created_at, :after_id)") + " ORDER BY created_at ASC, id ASC LIMIT 10",
+		},
+		{
+			name:            "after with where",
+			query:           NewGet("users", user).Where(Eq("name")).Keyset(Desc, "id").After(),
+			wantQueryString: fmt.Sprintf(templSelect, selects, "users", "(name=:name) AND id<:after_id") + " ORDER BY id DESC",
+		},
+		{
+			name:            "sqlserver after",
+			query:           NewGet("users", user, GetQueryOptions{Dialect: SQLServer}).Keyset(Asc, "created_at", "id").After(),
+			wantQueryString: fmt.Sprintf(templSelect, selects, "users", "(created_at>:after_created_at) OR (created_at=:after_created_at AND id>:after_id)") + " ORDER BY created_at ASC, id ASC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.wantQueryString {
+				t.Errorf("GetQuery.String() string = %+v ||  \n want %+v", got, tt.wantQueryString)
+			}
+		})
+	}
+
+	errs := []error{
+		NewGet("users", user).Keyset(Asc).Err(),
+		NewGet("users", user).Keyset(Asc, "nmae").Err(),
+		NewGet("users", user).After().Err(),
+	}
+	for i, err := range errs {
+		if err == nil {
+			t.Errorf("invalid keyset %d did not fail", i)
+		}
+	}
+
+	if _, err := decodeCursor([]string{"id"}, "not a cursor", user); err != ErrInvalidCursor {
+		t.Errorf("decodeCursor() error = %v, want %v", err, ErrInvalidCursor)
+	}
+}
+
+func Test_CountExists(t *testing.T) {
+
+	user := struct {
+		ID   string `db:"id,pk"`
+		Name string `db:"name"`
+	}{}
+
+	tests := []struct {
+		name            string
+		query           fmt.Stringer
+		wantQueryString string
+	}{
+		{
+			name:            "count",
+			query:           NewCount("users"),
+			wantQueryString: "SELECT COUNT(*) FROM users",
+		},
+		{
+			name:            "count where",
+			query:           NewCount("users").Where(Eq("name")),
+			wantQueryString: "SELECT COUNT(*) FROM users WHERE name=:name",
+		},
+		{
+			name:            "get count",
+			query:           NewGet("users", user).Where(Like("name", "pattern")).OrderBy("id", Asc).Limit(10).Offset(20).Count(),
+			wantQueryString: "SELECT COUNT(*) FROM users WHERE name LIKE :pattern",
+		},
+		{
+			name:            "get all count",
+			query:           NewGet("users", user).All().Count(),
+			wantQueryString: "SELECT COUNT(*) FROM users",
+		},
+		{
+			name:            "get exists",
+			query:           NewGet("users", user).Exists(),
+			wantQueryString: "SELECT EXISTS (SELECT 1 FROM users)",
+		},
+		{
+			name:            "get count without where",
+			query:           NewGet("users", user).Count(),
+			wantQueryString: "SELECT COUNT(*) FROM users",
+		},
+		{
+			name:            "sqlserver exists",
+			query:           NewExists("users", CountQueryOptions{Dialect: SQLServer}).Where(Eq("name")),
+			wantQueryString: "SELECT CASE WHEN EXISTS (SELECT 1 FROM users WHERE name=:name) THEN 1 ELSE 0 END",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.wantQueryString {
+				t.Errorf("String() string = %+v ||  \n want %+v", got, tt.wantQueryString)
+			}
+		})
+	}
+
+	account := struct {
+		Email string `db:"email"`
+	}{}
+	if err := NewGet("accounts", account).Count().Err(); err != nil {
+		t.Errorf("count of a table without a primary key error = %v", err)
+	}
+}
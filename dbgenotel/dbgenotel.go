@@ -0,0 +1,196 @@
+// Package dbgenotel instruments dbgen db functions with OpenTelemetry.
+//
+// Middleware creates a client span for every query run by the db functions of
+// a dbgen query, attaching the db.system, db.sql.table, db.operation and
+// sanitized db.statement attributes, the rows returned or affected, and any
+// error returned by the querier.
+package dbgenotel
+
+import (
+	"context"
+	"strings"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName the name of the tracer creating query spans
+const instrumentationName = "github.com/JonathanFejtek/go-dbgen/dbgenotel"
+
+// Attribute keys of query spans
+const (
+	DBSystem       = attribute.Key("db.system")
+	DBTable        = attribute.Key("db.sql.table")
+	DBOperation    = attribute.Key("db.operation")
+	DBStatement    = attribute.Key("db.statement")
+	DBQueryName    = attribute.Key("db.query.name")
+	DBRowsAffected = attribute.Key("db.rows_affected")
+)
+
+// Options optional arguments to create the tracing middleware
+type Options struct {
+	// TracerProvider the provider of the tracer creating spans, the global
+	// provider by default
+	TracerProvider trace.TracerProvider
+	// System the db.system attribute, derived from the query dialect by
+	// default
+	System string
+	// OmitStatement omit the db.statement attribute
+	OmitStatement bool
+}
+
+// Middleware get middleware creating a span per query, attach it to every
+// query with dbgen.Use or to a single query with its Use method
+func Middleware(opts ...Options) dbgen.Middleware {
+	var options Options
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	provider := options.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	tracer := provider.Tracer(instrumentationName)
+
+	return func(next dbgen.Exec) dbgen.Exec {
+		return func(ctx context.Context, info dbgen.QueryInfo) (dbgen.Result, error) {
+			operation := operationOf(info.Operation)
+
+			attrs := []attribute.KeyValue{
+				DBSystem.String(systemOf(options.System, info.Dialect)),
+				DBTable.String(info.Table),
+				DBOperation.String(operation),
+			}
+			if info.Name != "" {
+				attrs = append(attrs, DBQueryName.String(info.Name))
+			}
+			if !options.OmitStatement {
+				attrs = append(attrs, DBStatement.String(Sanitize(info.SQL)))
+			}
+
+			ctx, span := tracer.Start(
+				ctx,
+				spanName(info, operation),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			res, err := next(ctx, info)
+
+			if res.Rows >= 0 {
+				span.SetAttributes(DBRowsAffected.Int64(res.Rows))
+			}
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			return res, err
+		}
+	}
+}
+
+// Sanitize replace the string and numeric literals of a statement with ?,
+// and collapse its whitespace. Named parameters and quoted identifiers are
+// kept.
+func Sanitize(sql string) string {
+	var b strings.Builder
+	b.Grow(len(sql))
+
+	space := false
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+
+		switch {
+		case c == '\'':
+			// skip to the closing quote, '' escaping a quote
+			for i++; i < len(sql); i++ {
+				if sql[i] == '\'' {
+					if i+1 < len(sql) && sql[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			c = '?'
+		case c == '"' || c == '`':
+			// copy quoted identifiers unchanged
+			end := strings.IndexByte(sql[i+1:], c)
+			if end < 0 {
+				end = len(sql) - i - 1
+			} else {
+				end++
+			}
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteString(sql[i : i+end+1])
+			i += end
+			continue
+		case isDigit(c) && (i == 0 || !isIdentChar(sql[i-1])):
+			for i+1 < len(sql) && (isDigit(sql[i+1]) || sql[i+1] == '.') {
+				i++
+			}
+			c = '?'
+		case c == ' ' || c == '\n' || c == '\t' || c == '\r':
+			space = true
+			continue
+		}
+
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// operationOf the SQL operation of a db function, CREATE TABLE for
+// dbgen.OpCreateTable
+func operationOf(op dbgen.Operation) string {
+	return strings.ToUpper(strings.ReplaceAll(string(op.Statement()), "_", " "))
+}
+
+// systemOf the db.system of a dialect, following the OpenTelemetry names
+func systemOf(system string, d dbgen.Dialect) string {
+	if system != "" {
+		return system
+	}
+	if d == nil {
+		return "other_sql"
+	}
+
+	switch d.Name() {
+	case "postgres":
+		return "postgresql"
+	case "sqlserver":
+		return "mssql"
+	}
+	return d.Name()
+}
+
+// spanName the name of a query span, the query name if set
+func spanName(info dbgen.QueryInfo, operation string) string {
+	if info.Name != "" {
+		return info.Name
+	}
+	return operation + " " + info.Table
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c == ':' || c == '@' || isDigit(c) ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
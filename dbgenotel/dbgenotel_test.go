@@ -0,0 +1,162 @@
+package dbgenotel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type user struct {
+	ID   string `db:"id"`
+	Name string `db:"name"`
+}
+
+type querier struct {
+	err error
+}
+
+func (q querier) SelectContext(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
+	*dest.(*[]user) = []user{{ID: "1"}, {ID: "2"}}
+	return q.err
+}
+
+func (q querier) DeleteContext(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 3, q.err
+}
+
+func Test_Middleware(t *testing.T) {
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	mw := Middleware(Options{TracerProvider: provider})
+
+	ctx := context.Background()
+
+	list := dbgen.NewGetT[user]("users").Named("ListUsers").Use(mw).FnSelect()
+	if _, err := list(ctx, querier{}); err != nil {
+		t.Fatal(err)
+	}
+
+	wantErr := errors.New("connection reset")
+	del := dbgen.NewDelete("users", user{}, dbgen.DeleteQueryOptions{Dialect: dbgen.MySQL}).
+		Where("name = 'bob' AND id > 10").
+		Use(mw).
+		FnContext()
+	if _, err := del(ctx, querier{err: wantErr}); err != wantErr {
+		t.Fatalf("delete error = %v, want %v", err, wantErr)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+
+	tests := []struct {
+		name   string
+		status codes.Code
+		attrs  map[attribute.Key]string
+		rows   int64
+	}{
+		{
+			name:   "ListUsers",
+			status: codes.Unset,
+			attrs: map[attribute.Key]string{
+				DBSystem:    "postgresql",
+				DBTable:     "users",
+				DBOperation: "SELECT",
+				DBQueryName: "ListUsers",
+			},
+			rows: 2,
+		},
+		{
+			name:   "DELETE users",
+			status: codes.Error,
+			attrs: map[attribute.Key]string{
+				DBSystem:    "mysql",
+				DBTable:     "users",
+				DBOperation: "DELETE",
+				DBStatement: "DELETE FROM users WHERE name = ? AND id > ?",
+			},
+			rows: 3,
+		},
+	}
+
+	for i, tt := range tests {
+		span := spans[i]
+		if span.Name != tt.name || span.Status.Code != tt.status {
+			t.Errorf("span %d = %s %v, want %s %v", i, span.Name, span.Status.Code, tt.name, tt.status)
+		}
+
+		got := map[attribute.Key]attribute.Value{}
+		for _, attr := range span.Attributes {
+			got[attr.Key] = attr.Value
+		}
+		for key, want := range tt.attrs {
+			if got[key].AsString() != want {
+				t.Errorf("span %s %s = %q, want %q", tt.name, key, got[key].AsString(), want)
+			}
+		}
+		if got[DBRowsAffected].AsInt64() != tt.rows {
+			t.Errorf("span %s rows = %d, want %d", tt.name, got[DBRowsAffected].AsInt64(), tt.rows)
+		}
+	}
+
+	if len(spans[1].Events) != 1 || spans[1].Events[0].Name != "exception" {
+		t.Errorf("delete span did not record the error, events = %+v", spans[1].Events)
+	}
+}
+
+func Test_Sanitize(t *testing.T) {
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{
+			sql:  "SELECT id FROM users\n\tWHERE name = 'o''brien' AND age > 21.5",
+			want: "SELECT id FROM users WHERE name = ? AND age > ?",
+		},
+		{
+			sql:  `UPDATE "2fa" SET code=:code_0, n=$1 WHERE id=@p2`,
+			want: `UPDATE "2fa" SET code=:code_0, n=$1 WHERE id=@p2`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := Sanitize(tt.sql); got != tt.want {
+			t.Errorf("Sanitize string = %+v ||  \n want %+v", got, tt.want)
+		}
+	}
+}
+
+func Test_OperationOf(t *testing.T) {
+
+	tests := []struct {
+		op   dbgen.Operation
+		want string
+	}{
+		{dbgen.OpSelect, "SELECT"},
+		{dbgen.OpSelectOne, "SELECT"},
+		{dbgen.OpCount, "SELECT"},
+		{dbgen.OpExists, "SELECT"},
+		{dbgen.OpInsert, "INSERT"},
+		{dbgen.OpInsertMany, "INSERT"},
+		{dbgen.OpUpsert, "INSERT"},
+		{dbgen.OpUpdate, "UPDATE"},
+		{dbgen.OpDelete, "DELETE"},
+		{dbgen.OpCreateTable, "CREATE TABLE"},
+		{dbgen.OpExec, "EXEC"},
+	}
+
+	for _, tt := range tests {
+		if got := operationOf(tt.op); got != tt.want {
+			t.Errorf("operationOf(%s) string = %+v ||  \n want %+v", tt.op, got, tt.want)
+		}
+	}
+}
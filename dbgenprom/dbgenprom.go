@@ -0,0 +1,109 @@
+// Package dbgenprom collects Prometheus metrics of the queries run by dbgen
+// db functions.
+//
+// A Collector records the latency histogram, error counter and rows returned
+// or affected of every query, labelled by table, operation and query name,
+// through middleware attached with dbgen.Use or the Use method of a query.
+package dbgenprom
+
+import (
+	"context"
+	"time"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// labels the labels of every query metric, query being the name given to
+// the query with Named
+var labels = []string{"table", "operation", "query"}
+
+// Options optional arguments to create a new collector
+type Options struct {
+	// Namespace the namespace of the metric names, "dbgen" by default
+	Namespace string
+	// Buckets the buckets of the latency histogram in seconds,
+	// prometheus.DefBuckets by default
+	Buckets []float64
+}
+
+// Collector a prometheus.Collector of the queries run by dbgen db functions
+type Collector struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	rows     *prometheus.CounterVec
+}
+
+// New construct a new collector, to be registered with Prometheus and its
+// Middleware attached to the queries to measure
+func New(opts ...Options) *Collector {
+	var options Options
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.Namespace == "" {
+		options.Namespace = "dbgen"
+	}
+	if options.Buckets == nil {
+		options.Buckets = prometheus.DefBuckets
+	}
+
+	return &Collector{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: options.Namespace,
+			Name:      "query_duration_seconds",
+			Help:      "Latency of queries run by dbgen db functions.",
+			Buckets:   options.Buckets,
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: options.Namespace,
+			Name:      "query_errors_total",
+			Help:      "Queries run by dbgen db functions that returned an error.",
+		}, labels),
+		rows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: options.Namespace,
+			Name:      "query_rows_total",
+			Help:      "Rows returned or affected by queries run by dbgen db functions, where the querier reports them.",
+		}, labels),
+	}
+}
+
+// Describe implement prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.duration.Describe(ch)
+	c.errors.Describe(ch)
+	c.rows.Describe(ch)
+}
+
+// Collect implement prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.duration.Collect(ch)
+	c.errors.Collect(ch)
+	c.rows.Collect(ch)
+}
+
+// Middleware get middleware recording the metrics of every query
+func (c *Collector) Middleware() dbgen.Middleware {
+	return func(next dbgen.Exec) dbgen.Exec {
+		return func(ctx context.Context, info dbgen.QueryInfo) (dbgen.Result, error) {
+			start := time.Now()
+			res, err := next(ctx, info)
+			elapsed := time.Since(start)
+
+			values := []string{info.Table, operationOf(info.Operation), info.Name}
+			c.duration.WithLabelValues(values...).Observe(elapsed.Seconds())
+			if err != nil {
+				c.errors.WithLabelValues(values...).Inc()
+			} else if res.Rows >= 0 {
+				c.rows.WithLabelValues(values...).Add(float64(res.Rows))
+			}
+
+			return res, err
+		}
+	}
+}
+
+// operationOf the operation label of a db function, the statement it runs
+func operationOf(op dbgen.Operation) string {
+	return string(op.Statement())
+}
@@ -0,0 +1,107 @@
+package dbgenprom
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type user struct {
+	ID   string `db:"id"`
+	Name string `db:"name"`
+}
+
+type querier struct {
+	err error
+}
+
+func (q querier) SelectContext(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
+	*dest.(*[]user) = []user{{ID: "1"}, {ID: "2"}}
+	return q.err
+}
+
+func (q querier) InsertContext(ctx context.Context, query string, val interface{}) error {
+	return q.err
+}
+
+func (q querier) DeleteContext(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 3, q.err
+}
+
+func Test_Collector(t *testing.T) {
+
+	c := New()
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(c)
+
+	ctx := context.Background()
+	list := dbgen.NewGetT[user]("users").Named("ListUsers").Use(c.Middleware()).FnSelect()
+	insert := dbgen.NewInsert("users", user{}).Use(c.Middleware()).FnContext()
+	del := dbgen.NewDelete("users", user{}).Use(c.Middleware()).FnContext()
+
+	for i := 0; i < 2; i++ {
+		if _, err := list(ctx, querier{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := insert(ctx, querier{}, &user{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := del(ctx, querier{err: errors.New("timeout")}); err == nil {
+		t.Fatal("delete did not return the querier error")
+	}
+
+	if n := testutil.CollectAndCount(c, "dbgen_query_duration_seconds"); n != 3 {
+		t.Errorf("latency histograms = %d, want 3", n)
+	}
+
+	want := `
+# HELP dbgen_query_errors_total Queries run by dbgen db functions that returned an error.
+# TYPE dbgen_query_errors_total counter
+dbgen_query_errors_total{operation="delete",query="",table="users"} 1
+# HELP dbgen_query_rows_total Rows returned or affected by queries run by dbgen db functions, where the querier reports them.
+# TYPE dbgen_query_rows_total counter
+dbgen_query_rows_total{operation="insert",query="",table="users"} 1
+dbgen_query_rows_total{operation="select",query="ListUsers",table="users"} 4
+`
+	err := testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(want),
+		"dbgen_query_errors_total",
+		"dbgen_query_rows_total",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_OperationOf(t *testing.T) {
+
+	tests := []struct {
+		op   dbgen.Operation
+		want string
+	}{
+		{dbgen.OpSelect, "select"},
+		{dbgen.OpSelectOne, "select"},
+		{dbgen.OpCount, "select"},
+		{dbgen.OpExists, "select"},
+		{dbgen.OpInsert, "insert"},
+		{dbgen.OpInsertMany, "insert"},
+		{dbgen.OpUpsert, "insert"},
+		{dbgen.OpUpdate, "update"},
+		{dbgen.OpDelete, "delete"},
+		{dbgen.OpCreateTable, "create_table"},
+		{dbgen.OpExec, "exec"},
+	}
+
+	for _, tt := range tests {
+		if got := operationOf(tt.op); got != tt.want {
+			t.Errorf("operationOf(%s) string = %+v ||  \n want %+v", tt.op, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Package dbgentest provides test helpers for applications using dbgen.
+//
+// Golden diffs the query catalog of a dbgen.Registry against a checked-in
+// golden file, so changes to the SQL an application runs show up in review.
+// Run the tests with DBGEN_UPDATE_GOLDEN=1 to rewrite the golden files.
+package dbgentest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+)
+
+// UpdateEnv the environment variable rewriting golden files when set
+const UpdateEnv = "DBGEN_UPDATE_GOLDEN"
+
+// Golden compare the catalog of a registry with the golden file at path,
+// written as SQL, JSON or Markdown according to its .sql, .json or .md
+// extension
+func Golden(t testing.TB, r *dbgen.Registry, path string) {
+	t.Helper()
+
+	var got bytes.Buffer
+	var err error
+	switch ext := filepath.Ext(path); ext {
+	case ".sql":
+		err = r.WriteSQL(&got)
+	case ".json":
+		err = r.WriteJSON(&got)
+	case ".md":
+		err = r.WriteMarkdown(&got)
+	default:
+		t.Fatalf("dbgentest: unsupported golden file extension %q", ext)
+	}
+	if err != nil {
+		t.Fatalf("dbgentest: write catalog: %v", err)
+	}
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.WriteFile(path, got.Bytes(), 0o644); err != nil {
+			t.Fatalf("dbgentest: update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("dbgentest: read golden file: %v, run with %s=1 to create it", err, UpdateEnv)
+	}
+
+	if diff := Diff(string(want), got.String()); diff != "" {
+		t.Errorf("query catalog differs from %s, run with %s=1 to update it:\n%s", path, UpdateEnv, diff)
+	}
+}
+
+// Diff a line diff of want and got, lines of want prefixed with - and lines
+// of got with +, empty if they are equal
+func Diff(want, got string) string {
+	if want == got {
+		return ""
+	}
+
+	a := strings.Split(want, "\n")
+	b := strings.Split(got, "\n")
+
+	// longest common subsequence of lines
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var d strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&d, "  %s\n", a[i])
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			fmt.Fprintf(&d, "+ %s\n", b[j])
+			j++
+		default:
+			fmt.Fprintf(&d, "- %s\n", a[i])
+			i++
+		}
+	}
+
+	return d.String()
+}
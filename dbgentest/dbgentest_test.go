@@ -0,0 +1,46 @@
+package dbgentest
+
+import (
+	"testing"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+)
+
+type user struct {
+	ID        string `db:"id,pk"`
+	Name      string `db:"name"`
+	CreatedAt string `db:"created_at,readonly"`
+}
+
+func registry() *dbgen.Registry {
+	r := dbgen.NewRegistry()
+	dbgen.Must(r.RegisterGetOne("GetUser", dbgen.NewGet("users", user{})))
+	dbgen.Must(r.RegisterGet("ListUsersByName", dbgen.NewGet("users", user{}).Where("name=:name")))
+	dbgen.Must(r.RegisterInsert("CreateUser", dbgen.NewInsert("users", user{})))
+	dbgen.Must(r.RegisterUpdate("RenameUser", dbgen.NewUpdate("users", user{}).OmitReturns("id", "name", "created_at")))
+	dbgen.Must(r.RegisterDelete("DeleteUser", dbgen.NewDelete("users", user{})))
+	return r
+}
+
+func Test_Golden(t *testing.T) {
+	for _, path := range []string{
+		"testdata/queries.sql",
+		"testdata/queries.json",
+		"testdata/queries.md",
+	} {
+		Golden(t, registry(), path)
+	}
+}
+
+func Test_Diff(t *testing.T) {
+
+	want := "a\nb\nc"
+	got := "a\nc\nd"
+
+	if diff := Diff(want, got); diff != "  a\n- b\n  c\n+ d\n" {
+		t.Errorf("Diff string = %+v ||  \n want %+v", diff, "  a\n- b\n  c\n+ d\n")
+	}
+	if diff := Diff(want, want); diff != "" {
+		t.Errorf("Diff of equal strings = %q", diff)
+	}
+}
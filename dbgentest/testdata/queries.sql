@@ -0,0 +1,22 @@
+-- name: CreateUser :one
+INSERT INTO users (
+		id, name
+	) VALUES (
+		:id, :name
+	)
+	RETURNING users.id, users.name, users.created_at;
+
+-- name: DeleteUser :execrows
+DELETE FROM users WHERE id=:id;
+
+-- name: GetUser :one
+SELECT users.id, users.name, users.created_at FROM users WHERE id=:id;
+
+-- name: ListUsersByName :many
+SELECT users.id, users.name, users.created_at FROM users WHERE name=:name;
+
+-- name: RenameUser :exec
+UPDATE users
+	SET
+		name=:name
+	WHERE id=:id;
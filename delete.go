@@ -1,6 +1,7 @@
 package dbgen
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -9,10 +10,18 @@ type DeleteQuerier interface {
 	Delete(q string, args ...interface{}) (int64, error)
 }
 
+// DeleteContextQuerier interface that needs to be satisfied to construct a
+// context aware delete db function
+type DeleteContextQuerier interface {
+	DeleteContext(ctx context.Context, q string, args ...interface{}) (int64, error)
+}
+
 // MakeDeleteQueryArgs arguments required to make a delete query
 type MakeDeleteQueryArgs struct {
 	TableName   string
 	WhereClause string
+	Dialect     Dialect
+	Quoter      Quoter
 }
 
 // DeleteQuery represents a delete query
@@ -25,28 +34,99 @@ type DeleteQuery struct {
 func (q DeleteQuery) String() string {
 	return q.makeQuery(MakeDeleteQueryArgs{
 		TableName:   q.tableName,
-		WhereClause: q.whereClause,
+		WhereClause: q.whereString(),
+		Dialect:     q.dialect,
+		Quoter:      q.quoter,
 	})
 }
 
-// Where set the where clause of the delete query
-func (q DeleteQuery) Where(whereString string) DeleteQuery {
+// Err get the error building the delete query, returned by its db functions
+func (q DeleteQuery) Err() error {
+	return q.whereErr()
+}
+
+// Compile generate the query with the positional parameters of its dialect
+func (q DeleteQuery) Compile() CompiledQuery {
+	return q.compile(OpDelete, q.String(), q.Err())
+}
+
+// PrimaryKey set the primary key columns matched by the default where
+// clause of the delete query
+func (q DeleteQuery) PrimaryKey(fields ...string) DeleteQuery {
+	nq := q
+	nq.query = nq.query.setPrimaryKey(fields...)
+	return nq
+}
+
+// Where set the where clause of the delete query, a Predicate or a raw SQL
+// string
+func (q DeleteQuery) Where(where interface{}) DeleteQuery {
+	nq := q
+	nq.query = nq.query.where(where)
+	return nq
+}
+
+// Named name the delete query, passed to middleware
+func (q DeleteQuery) Named(name string) DeleteQuery {
 	nq := q
-	nq.query = nq.query.where(whereString)
+	nq.query = nq.query.named(name)
+	return nq
+}
+
+// Use attach middleware to the db functions of the delete query
+func (q DeleteQuery) Use(mw ...Middleware) DeleteQuery {
+	nq := q
+	nq.query = nq.query.use(mw...)
 	return nq
 }
 
 // Fn generate a db delete function
 func (q DeleteQuery) Fn() func(tx DeleteQuerier, args ...interface{}) (int64, error) {
 	qs := q.String()
+	lists := q.listExpander(qs)
+	buildErr := q.Err()
 	return func(tx DeleteQuerier, args ...interface{}) (int64, error) {
-		return tx.Delete(qs, args...)
+		if buildErr != nil {
+			return 0, buildErr
+		}
+		qs, args, err := lists.expand(qs, args)
+		if err != nil {
+			return 0, err
+		}
+		res, err := q.run(context.Background(), OpDelete, qs, args, func(ctx context.Context) (Result, error) {
+			n, err := tx.Delete(qs, args...)
+			return Result{Rows: n}, err
+		})
+		return res.Rows, err
+	}
+}
+
+// FnContext generate a db delete function honouring the context
+func (q DeleteQuery) FnContext() func(ctx context.Context, tx DeleteContextQuerier, args ...interface{}) (int64, error) {
+	qs := q.String()
+	lists := q.listExpander(qs)
+	buildErr := q.Err()
+	return func(ctx context.Context, tx DeleteContextQuerier, args ...interface{}) (int64, error) {
+		if buildErr != nil {
+			return 0, buildErr
+		}
+		qs, args, err := lists.expand(qs, args)
+		if err != nil {
+			return 0, err
+		}
+		res, err := q.run(ctx, OpDelete, qs, args, func(ctx context.Context) (Result, error) {
+			n, err := tx.DeleteContext(ctx, qs, args...)
+			return Result{Rows: n}, err
+		})
+		return res.Rows, err
 	}
 }
 
 // DeleteQueryOptions optional arguments to create a new delete query
 type DeleteQueryOptions struct {
 	MakeQuery func(args MakeDeleteQueryArgs) string
+	Dialect   Dialect
+	Quote     QuoteMode
 }
 
 // NewDelete construct a new delete query
@@ -57,15 +137,33 @@ func NewDelete(tableName string, i interface{}, opts ...DeleteQueryOptions) Dele
 		options = opts[0]
 	}
 
+	// the struct is only reflected for its primary key
+	var fields []field
+	var err error
+	if i != nil {
+		fields, err = getFieldsByTag("db", i)
+	}
+
+	quoter := Quoter{
+		Dialect: dialectOrDefault(options.Dialect),
+		Mode:    options.Quote,
+	}
+	primaryKey, primaryKeyErr := primaryKeyOf(tableName, fields, quoter)
+
 	q := DeleteQuery{
 		query: query{
-			tableName:   tableName,
-			whereClause: DefaultIdentityString,
+			tableName:     tableName,
+			columns:       columnsOf(fields, nil),
+			err:           err,
+			dialect:       dialectOrDefault(options.Dialect),
+			quoter:        quoter,
+			primaryKey:    primaryKey,
+			primaryKeyErr: primaryKeyErr,
 		},
 		makeQuery: func(args MakeDeleteQueryArgs) string {
 			return fmt.Sprintf(
 				templDelete,
-				args.TableName,
+				args.Quoter.Ident(args.TableName),
 				args.WhereClause,
 			)
 		},
@@ -0,0 +1,220 @@
+package dbgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ReturningStyle how a dialect returns the rows written by a statement
+type ReturningStyle int
+
+const (
+	// ReturningNone written rows cannot be returned by the statement
+	ReturningNone ReturningStyle = iota
+	// ReturningClause written rows are returned by a trailing RETURNING clause
+	ReturningClause
+	// ReturningOutput written rows are returned by an OUTPUT INSERTED clause
+	ReturningOutput
+)
+
+// Dialect the flavour of SQL a query is rendered for
+type Dialect interface {
+	// Name the name of the dialect
+	Name() string
+	// Placeholder the positional bind parameter for the nth (starting at 1)
+	// parameter of a query, named name in the query template
+	Placeholder(name string, n int) string
+	// QuoteIdent quote an identifier
+	QuoteIdent(ident string) string
+	// Returning how written rows are returned by the dialect
+	Returning() ReturningStyle
+}
+
+// ColumnTyper a dialect inferring column types from Go types, required to
+// create tables with columns lacking a type tag option
+type ColumnTyper interface {
+	// ColumnType the column type of a non pointer Go type, false if the
+	// dialect has none
+	ColumnType(t reflect.Type) (string, bool)
+}
+
+// BatchLimiter a dialect limiting the size of a statement, bounding the
+// rows of each bulk insert statement
+type BatchLimiter interface {
+	// MaxBindParams the maximum number of bind parameters of a statement
+	MaxBindParams() int
+	// MaxInsertRows the maximum number of rows of a VALUES list, zero for
+	// no limit
+	MaxInsertRows() int
+}
+
+var (
+	// Postgres the PostgreSQL dialect, used when no dialect is given
+	Postgres Dialect = postgresDialect{}
+	// MySQL the MySQL / MariaDB dialect
+	MySQL Dialect = mysqlDialect{}
+	// SQLite the SQLite dialect
+	SQLite Dialect = sqliteDialect{}
+	// SQLServer the Microsoft SQL Server dialect
+	SQLServer Dialect = sqlServerDialect{}
+)
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) Placeholder(name string, n int) string { return fmt.Sprintf("$%d", n) }
+
+func (postgresDialect) QuoteIdent(ident string) string { return quoteWith(ident, `"`, `"`) }
+
+func (postgresDialect) Returning() ReturningStyle { return ReturningClause }
+
+func (postgresDialect) ColumnType(t reflect.Type) (string, bool) { return postgresTypes.of(t) }
+
+func (postgresDialect) MaxBindParams() int { return 65535 }
+
+func (postgresDialect) MaxInsertRows() int { return 0 }
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) Placeholder(name string, n int) string { return "?" }
+
+func (mysqlDialect) QuoteIdent(ident string) string { return quoteWith(ident, "`", "`") }
+
+func (mysqlDialect) Returning() ReturningStyle { return ReturningNone }
+
+func (mysqlDialect) ColumnType(t reflect.Type) (string, bool) { return mysqlTypes.of(t) }
+
+func (mysqlDialect) MaxBindParams() int { return 65535 }
+
+func (mysqlDialect) MaxInsertRows() int { return 0 }
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) Placeholder(name string, n int) string { return "?" }
+
+func (sqliteDialect) QuoteIdent(ident string) string { return quoteWith(ident, `"`, `"`) }
+
+func (sqliteDialect) Returning() ReturningStyle { return ReturningClause }
+
+func (sqliteDialect) ColumnType(t reflect.Type) (string, bool) { return sqliteTypes.of(t) }
+
+func (sqliteDialect) MaxBindParams() int { return 32766 }
+
+func (sqliteDialect) MaxInsertRows() int { return 0 }
+
+type sqlServerDialect struct{}
+
+func (sqlServerDialect) Name() string { return "sqlserver" }
+
+func (sqlServerDialect) Placeholder(name string, n int) string { return fmt.Sprintf("@p%d", n) }
+
+func (sqlServerDialect) QuoteIdent(ident string) string { return quoteWith(ident, "[", "]") }
+
+func (sqlServerDialect) Returning() ReturningStyle { return ReturningOutput }
+
+func (sqlServerDialect) ColumnType(t reflect.Type) (string, bool) { return sqlServerTypes.of(t) }
+
+func (sqlServerDialect) MaxBindParams() int { return 2100 }
+
+func (sqlServerDialect) MaxInsertRows() int { return 1000 }
+
+// columnTypes the column types of a dialect for each kind of Go type
+type columnTypes struct {
+	text, boolean, smallint, integer, bigint, real, double, timestamp, bytes, json string
+}
+
+var (
+	postgresTypes = columnTypes{
+		text: "text", boolean: "boolean",
+		smallint: "smallint", integer: "integer", bigint: "bigint",
+		real: "real", double: "double precision",
+		timestamp: "timestamptz", bytes: "bytea", json: "jsonb",
+	}
+	mysqlTypes = columnTypes{
+		text: "varchar(255)", boolean: "boolean",
+		smallint: "smallint", integer: "int", bigint: "bigint",
+		real: "float", double: "double",
+		timestamp: "datetime(6)", bytes: "blob", json: "json",
+	}
+	sqliteTypes = columnTypes{
+		text: "text", boolean: "boolean",
+		smallint: "integer", integer: "integer", bigint: "integer",
+		real: "real", double: "real",
+		timestamp: "datetime", bytes: "blob", json: "text",
+	}
+	sqlServerTypes = columnTypes{
+		text: "nvarchar(255)", boolean: "bit",
+		smallint: "smallint", integer: "int", bigint: "bigint",
+		real: "real", double: "float",
+		timestamp: "datetimeoffset", bytes: "varbinary(max)", json: "nvarchar(max)",
+	}
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// of the column type of a Go type
+func (c columnTypes) of(t reflect.Type) (string, bool) {
+	switch t {
+	case timeType:
+		return c.timestamp, true
+	case rawMessageType:
+		return c.json, true
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return c.text, true
+	case reflect.Bool:
+		return c.boolean, true
+	case reflect.Int8, reflect.Int16, reflect.Uint8:
+		return c.smallint, true
+	case reflect.Int32, reflect.Uint16:
+		return c.integer, true
+	case reflect.Int, reflect.Int64, reflect.Uint32, reflect.Uint, reflect.Uint64:
+		return c.bigint, true
+	case reflect.Float32:
+		return c.real, true
+	case reflect.Float64:
+		return c.double, true
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return c.bytes, true
+		}
+	}
+
+	return "", false
+}
+
+// quoteWith wrap an identifier in open and close quotes, doubling any
+// closing quote inside the identifier
+func quoteWith(ident string, open string, close string) string {
+	return open + strings.ReplaceAll(ident, close, close+close) + close
+}
+
+// dialectOrDefault get the dialect, falling back to Postgres
+func dialectOrDefault(d Dialect) Dialect {
+	if d == nil {
+		return Postgres
+	}
+	return d
+}
+
+// returningStyle get how the return fields are returned by the dialect,
+// nothing is returned when there are no return fields
+func returningStyle(d Dialect, returnFields Columns) ReturningStyle {
+	if len(returnFields.Fields) == 0 {
+		return ReturningNone
+	}
+	return dialectOrDefault(d).Returning()
+}
@@ -0,0 +1,253 @@
+package dbgen
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// listParamPattern matches the list parameters of a query, [NOT] IN (:name)
+var listParamPattern = regexp.MustCompile(`(?i)\b(NOT\s+)?IN\s*\(\s*:([A-Za-z0-9_]+)\s*\)`)
+
+// listParam a list parameter of a query, spanning [start, end)
+type listParam struct {
+	start, end int
+	name       string
+	negated    bool
+}
+
+// listExpander expand the list parameters of a query bound to slices into
+// a parameter per element, caching the expanded query per slice lengths
+type listExpander struct {
+	query string
+	// params the distinct parameters of the query in order
+	params []string
+	// lists the list parameters of the query, outside its quoted sections
+	lists []listParam
+	// operands the operands of In predicates by parameter, whose comparison
+	// to an empty list renders 1=0
+	operands map[string][]string
+	expanded sync.Map
+}
+
+// newListExpander get the expander of a query, nil if the query has no list
+// parameters
+func newListExpander(qs string, operands map[string][]string) *listExpander {
+	lists := listParamsOf(qs)
+	if len(lists) == 0 {
+		return nil
+	}
+
+	return &listExpander{
+		query:    qs,
+		params:   distinctParams(qs),
+		lists:    lists,
+		operands: operands,
+	}
+}
+
+// listParamsOf the list parameters of a query, skipping quoted sections as
+// Compile does
+func listParamsOf(qs string) []listParam {
+	var quoted [][2]int
+	for i := 0; i < len(qs); i++ {
+		if c := qs[i]; c == '\'' || c == '"' || c == '`' {
+			end := skipQuoted(qs, i, c)
+			quoted = append(quoted, [2]int{i, end})
+			i = end - 1
+		}
+	}
+
+	var lists []listParam
+	for _, m := range listParamPattern.FindAllStringSubmatchIndex(qs, -1) {
+		if inSections(quoted, m[0]) {
+			continue
+		}
+		lists = append(lists, listParam{
+			start:   m[0],
+			end:     m[1],
+			name:    qs[m[4]:m[5]],
+			negated: m[2] >= 0,
+		})
+	}
+	return lists
+}
+
+// inSections whether an index is in any of the sections
+func inSections(sections [][2]int, i int) bool {
+	for _, s := range sections {
+		if i >= s[0] && i < s[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// isList whether a parameter is a list parameter of the query
+func (e *listExpander) isList(name string) bool {
+	for _, l := range e.lists {
+		if l.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// expand get the query qs and arguments with the list parameters bound to
+// slices expanded. Arguments are bound by name as for
+// CompiledQuery.BindArgs, and returned as a single map when a list is
+// expanded.
+func (e *listExpander) expand(qs string, args []interface{}) (string, []interface{}, error) {
+	if e == nil {
+		return qs, args, nil
+	}
+
+	values, err := e.namedValues(args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// the length of each list parameter bound to a slice, in order
+	var key strings.Builder
+	lengths := map[string]int{}
+	for _, name := range e.params {
+		if !e.isList(name) || !isList(values[name]) {
+			continue
+		}
+		n := reflect.ValueOf(values[name]).Len()
+		lengths[name] = n
+		fmt.Fprintf(&key, "%s=%d,", name, n)
+	}
+
+	if len(lengths) == 0 {
+		return qs, args, nil
+	}
+
+	expanded := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		n, ok := lengths[name]
+		if !ok {
+			expanded[name] = value
+			continue
+		}
+		v := reflect.ValueOf(value)
+		for i := 0; i < n; i++ {
+			expanded[name+"_"+strconv.Itoa(i)] = v.Index(i).Interface()
+		}
+	}
+
+	expandedQuery, ok := e.expanded.Load(key.String())
+	if !ok {
+		q, err := e.expandQuery(lengths)
+		if err != nil {
+			return "", nil, err
+		}
+		expandedQuery, _ = e.expanded.LoadOrStore(key.String(), q)
+	}
+
+	return expandedQuery.(string), []interface{}{expanded}, nil
+}
+
+// expandQuery render the query with its list parameters expanded. An In
+// predicate compared to an empty list renders 1=0, a raw IN (NULL), and an
+// empty list of a raw NOT IN is an error, as no list is empty in SQL.
+func (e *listExpander) expandQuery(lengths map[string]int) (string, error) {
+	var sb strings.Builder
+	last := 0
+	for _, l := range e.lists {
+		n, ok := lengths[l.name]
+		if !ok {
+			continue
+		}
+
+		start, match := l.start, e.query[l.start:l.end]
+		list := match[:strings.LastIndex(match, "(")]
+		switch {
+		case n > 0:
+			params := make([]string, n)
+			for i := range params {
+				params[i] = ":" + l.name + "_" + strconv.Itoa(i)
+			}
+			list += "(" + strings.Join(params, ", ") + ")"
+		case l.negated:
+			return "", fmt.Errorf("dbgen: empty list bound to NOT IN (:%s)", l.name)
+		default:
+			list += "(NULL)"
+			if operand, ok := e.operandOf(l); ok {
+				start, list = l.start-len(operand)-1, "1=0"
+			}
+		}
+
+		sb.WriteString(e.query[last:start])
+		sb.WriteString(list)
+		last = l.end
+	}
+	sb.WriteString(e.query[last:])
+
+	return sb.String(), nil
+}
+
+// operandOf the operand of the In predicate rendering a list parameter
+func (e *listExpander) operandOf(l listParam) (string, bool) {
+	for _, operand := range e.operands[l.name] {
+		if strings.HasSuffix(e.query[:l.start], operand+" ") {
+			return operand, true
+		}
+	}
+	return "", false
+}
+
+// namedValues get the values of the parameters of the query by name
+func (e *listExpander) namedValues(args []interface{}) (map[string]interface{}, error) {
+	return namedArgs(e.params, args)
+}
+
+// namedArgs get the arguments given to a db function by parameter name: a
+// single struct or map is bound by name, otherwise the arguments are the
+// values of the distinct parameters in order
+func namedArgs(params []string, args []interface{}) (map[string]interface{}, error) {
+	if len(args) == 1 && isNamedArg(args[0]) {
+		return namedValues(args[0])
+	}
+
+	if len(args) != len(params) {
+		return nil, fmt.Errorf(
+			"dbgen: query has %d parameters, got %d arguments",
+			len(params), len(args),
+		)
+	}
+
+	values := make(map[string]interface{}, len(args))
+	for i, name := range params {
+		values[name] = args[i]
+	}
+	return values, nil
+}
+
+// distinctParams the distinct named parameters of a query in order
+func distinctParams(qs string) []string {
+	var params []string
+	seen := map[string]bool{}
+	for _, name := range compilePositional(qs, Postgres).Params {
+		if !seen[name] {
+			seen[name] = true
+			params = append(params, name)
+		}
+	}
+	return params
+}
+
+// isList whether a value is a slice or array bound as a list, rather than a
+// byte slice bound as a single value
+func isList(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	return (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && !isBytes(v.Type())
+}
+
+// isBytes whether a type is a byte slice, bound as a single value
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
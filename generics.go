@@ -0,0 +1,462 @@
+package dbgen
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// GetQueryT a get query selecting rows of type T
+type GetQueryT[T any] struct {
+	q GetQuery
+}
+
+// NewGetT construct a new get query selecting the columns of T
+func NewGetT[T any](tableName string, opts ...GetQueryOptions) GetQueryT[T] {
+	var zero T
+	return GetQueryT[T]{q: NewGet(tableName, zero, opts...)}
+}
+
+// Query get the untyped get query
+func (q GetQueryT[T]) Query() GetQuery {
+	return q.q
+}
+
+// Named name the get query, passed to middleware
+func (q GetQueryT[T]) Named(name string) GetQueryT[T] {
+	return GetQueryT[T]{q: q.q.Named(name)}
+}
+
+// Use attach middleware to the db functions of the get query
+func (q GetQueryT[T]) Use(mw ...Middleware) GetQueryT[T] {
+	return GetQueryT[T]{q: q.q.Use(mw...)}
+}
+
+// OmitReturns omit return/select fields from the get query
+func (q GetQueryT[T]) OmitReturns(fields ...string) GetQueryT[T] {
+	return GetQueryT[T]{q: q.q.OmitReturns(fields...)}
+}
+
+// PrimaryKey set the primary key columns matched by the default where
+// clause of the get query
+func (q GetQueryT[T]) PrimaryKey(fields ...string) GetQueryT[T] {
+	return GetQueryT[T]{q: q.q.PrimaryKey(fields...)}
+}
+
+// Where set the where clause of the get query
+func (q GetQueryT[T]) Where(where interface{}) GetQueryT[T] {
+	return GetQueryT[T]{q: q.q.Where(where)}
+}
+
+// All select every row of the table rather than the row matching the
+// primary key
+func (q GetQueryT[T]) All() GetQueryT[T] {
+	return GetQueryT[T]{q: q.q.All()}
+}
+
+// OrderBy order the selected rows by a column
+func (q GetQueryT[T]) OrderBy(column string, direction SortDirection) GetQueryT[T] {
+	return GetQueryT[T]{q: q.q.OrderBy(column, direction)}
+}
+
+// Limit select at most n rows
+func (q GetQueryT[T]) Limit(n int) GetQueryT[T] {
+	return GetQueryT[T]{q: q.q.Limit(n)}
+}
+
+// LimitParam select at most the number of rows bound to a parameter
+func (q GetQueryT[T]) LimitParam(param string) GetQueryT[T] {
+	return GetQueryT[T]{q: q.q.LimitParam(param)}
+}
+
+// Offset skip the first n selected rows
+func (q GetQueryT[T]) Offset(n int) GetQueryT[T] {
+	return GetQueryT[T]{q: q.q.Offset(n)}
+}
+
+// OffsetParam skip the number of selected rows bound to a parameter
+func (q GetQueryT[T]) OffsetParam(param string) GetQueryT[T] {
+	return GetQueryT[T]{q: q.q.OffsetParam(param)}
+}
+
+// String generate the get query as a string query
+func (q GetQueryT[T]) String() string {
+	return q.q.String()
+}
+
+// Err get the error building the get query, returned by its db functions
+func (q GetQueryT[T]) Err() error {
+	return q.q.Err()
+}
+
+// Count the query counting the rows matching the where clause of the get
+// query, every row unless a where clause is set
+func (q GetQueryT[T]) Count() CountQuery {
+	return q.q.Count()
+}
+
+// Exists the query checking whether any row matches the where clause of the
+// get query, any row unless a where clause is set
+func (q GetQueryT[T]) Exists() ExistsQuery {
+	return q.q.Exists()
+}
+
+// FnSelect generate the get query as a function selecting rows of T
+func (q GetQueryT[T]) FnSelect() func(ctx context.Context, tx SelectContextQuerier, args ...interface{}) ([]T, error) {
+	fn := q.q.FnSelectContext()
+	return func(ctx context.Context, tx SelectContextQuerier, args ...interface{}) ([]T, error) {
+		var rows []T
+		if err := fn(ctx, tx, &rows, args...); err != nil {
+			return nil, err
+		}
+		return rows, nil
+	}
+}
+
+// FnSelectOne generate the get query as a function selecting a single row of T
+func (q GetQueryT[T]) FnSelectOne() func(ctx context.Context, tx SelectOneContextQuerier, args ...interface{}) (T, error) {
+	fn := q.q.FnSelectOneContext()
+	return func(ctx context.Context, tx SelectOneContextQuerier, args ...interface{}) (T, error) {
+		var row T
+		err := fn(ctx, tx, &row, args...)
+		return row, err
+	}
+}
+
+// Page a page of rows of T selected by keyset pagination
+type Page[T any] struct {
+	Rows []T
+	// Next the cursor of the following page, empty on the last page
+	Next string
+}
+
+// Keyset order the selected rows by the key columns to select them a page
+// at a time with FnPage
+func (q GetQueryT[T]) Keyset(direction SortDirection, columns ...string) GetQueryT[T] {
+	return GetQueryT[T]{q: q.q.Keyset(direction, columns...)}
+}
+
+// FnPage generate the keyset query as a function selecting a page of Limit
+// rows of T, starting after the row encoded by the cursor of the previous
+// page or at the first row when the cursor is empty
+func (q GetQueryT[T]) FnPage() func(ctx context.Context, tx SelectContextQuerier, cursor string, args ...interface{}) (Page[T], error) {
+	var buildErr error
+	limit, limitErr := strconv.Atoi(q.q.limit)
+	switch {
+	case len(q.q.keyset) == 0:
+		buildErr = errors.New("dbgen: keyset pagination requires a Keyset")
+	case limitErr != nil || limit <= 0:
+		buildErr = errors.New("dbgen: keyset pagination requires a positive Limit")
+	}
+
+	// select a row more than the page to know if there is a next page
+	first := q.q.Limit(limit + 1)
+	params := distinctParams(first.String())
+	selectFirst := first.FnSelectContext()
+	selectAfter := first.After().FnSelectContext()
+
+	return func(ctx context.Context, tx SelectContextQuerier, cursor string, args ...interface{}) (Page[T], error) {
+		if buildErr != nil {
+			return Page[T]{}, buildErr
+		}
+
+		var rows []T
+		if cursor == "" {
+			if err := selectFirst(ctx, tx, &rows, args...); err != nil {
+				return Page[T]{}, err
+			}
+		} else {
+			var zero T
+			after, err := decodeCursor(q.q.keyset, cursor, zero)
+			if err != nil {
+				return Page[T]{}, err
+			}
+
+			named, err := namedArgs(params, args)
+			if err != nil {
+				return Page[T]{}, err
+			}
+			values := make(map[string]interface{}, len(named)+len(after))
+			for name, value := range named {
+				values[name] = value
+			}
+			for name, value := range after {
+				values[name] = value
+			}
+
+			if err := selectAfter(ctx, tx, &rows, values); err != nil {
+				return Page[T]{}, err
+			}
+		}
+
+		if len(rows) <= limit {
+			return Page[T]{Rows: rows}, nil
+		}
+
+		rows = rows[:limit]
+		next, err := encodeCursor(q.q.keyset, rows[limit-1])
+		if err != nil {
+			return Page[T]{}, err
+		}
+		return Page[T]{Rows: rows, Next: next}, nil
+	}
+}
+
+// InsertQueryT an insert query inserting rows of type T
+type InsertQueryT[T any] struct {
+	q InsertQuery
+}
+
+// NewInsertT construct a new insert query inserting the columns of T
+func NewInsertT[T any](tableName string, opts ...InsertQueryOptions) InsertQueryT[T] {
+	var zero T
+	return InsertQueryT[T]{q: NewInsert(tableName, zero, opts...)}
+}
+
+// Query get the untyped insert query
+func (q InsertQueryT[T]) Query() InsertQuery {
+	return q.q
+}
+
+// Named name the insert query, passed to middleware
+func (q InsertQueryT[T]) Named(name string) InsertQueryT[T] {
+	return InsertQueryT[T]{q: q.q.Named(name)}
+}
+
+// Use attach middleware to the db functions of the insert query
+func (q InsertQueryT[T]) Use(mw ...Middleware) InsertQueryT[T] {
+	return InsertQueryT[T]{q: q.q.Use(mw...)}
+}
+
+// OmitValues omit value fields to insert from the query
+func (q InsertQueryT[T]) OmitValues(fields ...string) InsertQueryT[T] {
+	return InsertQueryT[T]{q: q.q.OmitValues(fields...)}
+}
+
+// OmitReturns omit return fields from the insert query
+func (q InsertQueryT[T]) OmitReturns(fields ...string) InsertQueryT[T] {
+	return InsertQueryT[T]{q: q.q.OmitReturns(fields...)}
+}
+
+// MaxBindParams set the maximum number of bind parameters used by each
+// statement of a bulk insert
+func (q InsertQueryT[T]) MaxBindParams(n int) InsertQueryT[T] {
+	return InsertQueryT[T]{q: q.q.MaxBindParams(n)}
+}
+
+// String generate the query as a string
+func (q InsertQueryT[T]) String() string {
+	return q.q.String()
+}
+
+// Err get the error building the insert query, returned by its db functions
+func (q InsertQueryT[T]) Err() error {
+	return q.q.Err()
+}
+
+// Fn generate the query as a function inserting a row of T
+func (q InsertQueryT[T]) Fn() func(ctx context.Context, tx InsertContextQuerier, row *T) error {
+	fn := q.q.FnContext()
+	return func(ctx context.Context, tx InsertContextQuerier, row *T) error {
+		return fn(ctx, tx, row)
+	}
+}
+
+// FnMany generate the query as a function inserting rows of T in bulk
+func (q InsertQueryT[T]) FnMany() func(ctx context.Context, tx BulkInsertContextQuerier, rows []T) error {
+	fn := q.q.FnManyContext()
+	return func(ctx context.Context, tx BulkInsertContextQuerier, rows []T) error {
+		return fn(ctx, tx, rows)
+	}
+}
+
+// UpdateQueryT an update query updating rows of type T
+type UpdateQueryT[T any] struct {
+	q UpdateQuery
+}
+
+// NewUpdateT construct a new update query updating the columns of T
+func NewUpdateT[T any](tableName string, opts ...UpdateQueryOptions) UpdateQueryT[T] {
+	var zero T
+	return UpdateQueryT[T]{q: NewUpdate(tableName, zero, opts...)}
+}
+
+// Query get the untyped update query
+func (q UpdateQueryT[T]) Query() UpdateQuery {
+	return q.q
+}
+
+// Named name the update query, passed to middleware
+func (q UpdateQueryT[T]) Named(name string) UpdateQueryT[T] {
+	return UpdateQueryT[T]{q: q.q.Named(name)}
+}
+
+// Use attach middleware to the db functions of the update query
+func (q UpdateQueryT[T]) Use(mw ...Middleware) UpdateQueryT[T] {
+	return UpdateQueryT[T]{q: q.q.Use(mw...)}
+}
+
+// OmitValues omit values to update from the query
+func (q UpdateQueryT[T]) OmitValues(fields ...string) UpdateQueryT[T] {
+	return UpdateQueryT[T]{q: q.q.OmitValues(fields...)}
+}
+
+// OmitReturns omit fields to return from the query
+func (q UpdateQueryT[T]) OmitReturns(fields ...string) UpdateQueryT[T] {
+	return UpdateQueryT[T]{q: q.q.OmitReturns(fields...)}
+}
+
+// PrimaryKey set the primary key columns matched by the default where
+// clause of the query
+func (q UpdateQueryT[T]) PrimaryKey(fields ...string) UpdateQueryT[T] {
+	return UpdateQueryT[T]{q: q.q.PrimaryKey(fields...)}
+}
+
+// Where set the where clause of the query
+func (q UpdateQueryT[T]) Where(where interface{}) UpdateQueryT[T] {
+	return UpdateQueryT[T]{q: q.q.Where(where)}
+}
+
+// String generate the query as a string
+func (q UpdateQueryT[T]) String() string {
+	return q.q.String()
+}
+
+// Err get the error building the update query, returned by its db functions
+func (q UpdateQueryT[T]) Err() error {
+	return q.q.Err()
+}
+
+// Fn generate the query as a function updating a row of T
+func (q UpdateQueryT[T]) Fn() func(ctx context.Context, tx UpdateContextQuerier, row *T) error {
+	fn := q.q.FnContext()
+	return func(ctx context.Context, tx UpdateContextQuerier, row *T) error {
+		return fn(ctx, tx, row)
+	}
+}
+
+// UpsertQueryT an upsert query writing rows of type T
+type UpsertQueryT[T any] struct {
+	q UpsertQuery
+}
+
+// NewUpsertT construct a new upsert query writing the columns of T
+func NewUpsertT[T any](tableName string, opts ...UpsertQueryOptions) UpsertQueryT[T] {
+	var zero T
+	return UpsertQueryT[T]{q: NewUpsert(tableName, zero, opts...)}
+}
+
+// Query get the untyped upsert query
+func (q UpsertQueryT[T]) Query() UpsertQuery {
+	return q.q
+}
+
+// Named name the upsert query, passed to middleware
+func (q UpsertQueryT[T]) Named(name string) UpsertQueryT[T] {
+	return UpsertQueryT[T]{q: q.q.Named(name)}
+}
+
+// Use attach middleware to the db functions of the upsert query
+func (q UpsertQueryT[T]) Use(mw ...Middleware) UpsertQueryT[T] {
+	return UpsertQueryT[T]{q: q.q.Use(mw...)}
+}
+
+// OmitValues omit value fields to insert from the query
+func (q UpsertQueryT[T]) OmitValues(fields ...string) UpsertQueryT[T] {
+	return UpsertQueryT[T]{q: q.q.OmitValues(fields...)}
+}
+
+// OmitReturns omit return fields from the query
+func (q UpsertQueryT[T]) OmitReturns(fields ...string) UpsertQueryT[T] {
+	return UpsertQueryT[T]{q: q.q.OmitReturns(fields...)}
+}
+
+// OnConflict set the conflict target columns of the query
+func (q UpsertQueryT[T]) OnConflict(fields ...string) UpsertQueryT[T] {
+	return UpsertQueryT[T]{q: q.q.OnConflict(fields...)}
+}
+
+// DoNothing ignore rows that conflict instead of updating them
+func (q UpsertQueryT[T]) DoNothing() UpsertQueryT[T] {
+	return UpsertQueryT[T]{q: q.q.DoNothing()}
+}
+
+// DoUpdate set the columns updated from the excluded row on conflict
+func (q UpsertQueryT[T]) DoUpdate(fields ...string) UpsertQueryT[T] {
+	return UpsertQueryT[T]{q: q.q.DoUpdate(fields...)}
+}
+
+// OmitUpdates omit columns from being updated on conflict
+func (q UpsertQueryT[T]) OmitUpdates(fields ...string) UpsertQueryT[T] {
+	return UpsertQueryT[T]{q: q.q.OmitUpdates(fields...)}
+}
+
+// String generate the query as a string
+func (q UpsertQueryT[T]) String() string {
+	return q.q.String()
+}
+
+// Err get the error building the upsert query, returned by its db functions
+func (q UpsertQueryT[T]) Err() error {
+	return q.q.Err()
+}
+
+// Fn generate the query as a function upserting a row of T
+func (q UpsertQueryT[T]) Fn() func(ctx context.Context, tx UpsertContextQuerier, row *T) error {
+	fn := q.q.FnContext()
+	return func(ctx context.Context, tx UpsertContextQuerier, row *T) error {
+		return fn(ctx, tx, row)
+	}
+}
+
+// DeleteQueryT a delete query deleting rows of type T
+type DeleteQueryT[T any] struct {
+	q DeleteQuery
+}
+
+// NewDeleteT construct a new delete query matching the primary key of T
+func NewDeleteT[T any](tableName string, opts ...DeleteQueryOptions) DeleteQueryT[T] {
+	var zero T
+	return DeleteQueryT[T]{q: NewDelete(tableName, zero, opts...)}
+}
+
+// Query get the untyped delete query
+func (q DeleteQueryT[T]) Query() DeleteQuery {
+	return q.q
+}
+
+// Named name the delete query, passed to middleware
+func (q DeleteQueryT[T]) Named(name string) DeleteQueryT[T] {
+	return DeleteQueryT[T]{q: q.q.Named(name)}
+}
+
+// Use attach middleware to the db functions of the delete query
+func (q DeleteQueryT[T]) Use(mw ...Middleware) DeleteQueryT[T] {
+	return DeleteQueryT[T]{q: q.q.Use(mw...)}
+}
+
+// PrimaryKey set the primary key columns matched by the default where
+// clause of the delete query
+func (q DeleteQueryT[T]) PrimaryKey(fields ...string) DeleteQueryT[T] {
+	return DeleteQueryT[T]{q: q.q.PrimaryKey(fields...)}
+}
+
+// Where set the where clause of the delete query
+func (q DeleteQueryT[T]) Where(where interface{}) DeleteQueryT[T] {
+	return DeleteQueryT[T]{q: q.q.Where(where)}
+}
+
+// String the delete query as a string query
+func (q DeleteQueryT[T]) String() string {
+	return q.q.String()
+}
+
+// Err get the error building the delete query, returned by its db functions
+func (q DeleteQueryT[T]) Err() error {
+	return q.q.Err()
+}
+
+// Fn generate a db delete function
+func (q DeleteQueryT[T]) Fn() func(ctx context.Context, tx DeleteContextQuerier, args ...interface{}) (int64, error) {
+	return q.q.FnContext()
+}
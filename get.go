@@ -1,7 +1,10 @@
 package dbgen
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // SelectQuerier interface required to build a select rows db function
@@ -14,16 +17,59 @@ type SelectOneQuerier interface {
 	SelectOne(query string, i interface{}, args ...interface{}) error
 }
 
+// SelectContextQuerier interface required to build a context aware select
+// rows db function
+type SelectContextQuerier interface {
+	SelectContext(ctx context.Context, query string, dest interface{}, args ...interface{}) error
+}
+
+// SelectOneContextQuerier interface required to build a context aware select
+// row db function
+type SelectOneContextQuerier interface {
+	SelectOneContext(ctx context.Context, query string, i interface{}, args ...interface{}) error
+}
+
 // MakeUpdateQueryArgs arguments required to make an update query
 type MakeGetQueryArgs struct {
-	TableName    string
+	TableName string
+	// WhereClause the where clause of the query, empty to select every row
 	WhereClause  string
 	ReturnFields Columns
+	OrderBy      []OrderTerm
+	// Limit the maximum number of rows selected, a number or a :param,
+	// empty for no limit
+	Limit string
+	// Offset the number of rows skipped, a number or a :param, empty for
+	// no offset
+	Offset  string
+	Dialect Dialect
+	Quoter  Quoter
+}
+
+// SortDirection the direction rows are ordered by a column
+type SortDirection string
+
+const (
+	// Asc order rows by ascending values of a column
+	Asc SortDirection = "ASC"
+	// Desc order rows by descending values of a column
+	Desc SortDirection = "DESC"
+)
+
+// OrderTerm a column rows are ordered by
+type OrderTerm struct {
+	Column    string
+	Direction SortDirection
 }
 
 // GetQuery represents a get query
 type GetQuery struct {
 	query
+	all       bool
+	keyset    []string
+	orderBy   []OrderTerm
+	limit     string
+	offset    string
 	makeQuery func(args MakeGetQueryArgs) string
 }
 
@@ -34,72 +80,261 @@ func (q GetQuery) OmitReturns(fields ...string) GetQuery {
 	return nq
 }
 
-// Where set the where clause of the get query
-func (q GetQuery) Where(whereString string) GetQuery {
+// PrimaryKey set the primary key columns matched by the default where
+// clause of the get query
+func (q GetQuery) PrimaryKey(fields ...string) GetQuery {
+	nq := q
+	nq.query = nq.query.setPrimaryKey(fields...)
+	return nq
+}
+
+// Where set the where clause of the get query, a Predicate or a raw SQL
+// string
+func (q GetQuery) Where(where interface{}) GetQuery {
+	nq := q
+	nq.query = nq.query.where(where)
+	return nq
+}
+
+// All select every row of the table rather than the row matching the
+// primary key, unless a where clause is set
+func (q GetQuery) All() GetQuery {
+	nq := q
+	nq.all = true
+	return nq
+}
+
+// OrderBy order the selected rows by a column, after the columns of
+// previous calls
+func (q GetQuery) OrderBy(column string, direction SortDirection) GetQuery {
+	nq := q
+	nq.orderBy = append(q.orderBy[:len(q.orderBy):len(q.orderBy)], OrderTerm{
+		Column:    column,
+		Direction: direction,
+	})
+
+	if nq.err == nil {
+		nq.err = nq.checkColumn(column)
+	}
+	if nq.err == nil && direction != Asc && direction != Desc {
+		nq.err = fmt.Errorf("dbgen: invalid sort direction %q of %s", direction, column)
+	}
+	return nq
+}
+
+// Limit select at most n rows
+func (q GetQuery) Limit(n int) GetQuery {
+	nq := q
+	nq.limit = strconv.Itoa(n)
+	if nq.err == nil && n < 0 {
+		nq.err = fmt.Errorf("dbgen: negative limit %d", n)
+	}
+	return nq
+}
+
+// LimitParam select at most the number of rows bound to a parameter, given
+// as :name
+func (q GetQuery) LimitParam(param string) GetQuery {
 	nq := q
-	nq.query = nq.query.where(whereString)
+	nq.limit = param
+	if nq.err == nil {
+		nq.err = checkNamedParam(param)
+	}
+	return nq
+}
+
+// Offset skip the first n selected rows
+func (q GetQuery) Offset(n int) GetQuery {
+	nq := q
+	nq.offset = strconv.Itoa(n)
+	if nq.err == nil && n < 0 {
+		nq.err = fmt.Errorf("dbgen: negative offset %d", n)
+	}
+	return nq
+}
+
+// OffsetParam skip the number of selected rows bound to a parameter, given
+// as :name
+func (q GetQuery) OffsetParam(param string) GetQuery {
+	nq := q
+	nq.offset = param
+	if nq.err == nil {
+		nq.err = checkNamedParam(param)
+	}
 	return nq
 }
 
 // String generate the get query as a string query
 func (q GetQuery) String() string {
 
+	where := q.whereString()
+	if q.all && q.whereClause == "" {
+		where = ""
+	}
+
 	return q.makeQuery(MakeGetQueryArgs{
 		TableName:    q.tableName,
-		WhereClause:  q.whereClause,
+		WhereClause:  where,
 		ReturnFields: q.returnFields,
+		OrderBy:      q.orderBy,
+		Limit:        q.limit,
+		Offset:       q.offset,
+		Dialect:      q.dialect,
+		Quoter:       q.quoter,
 	})
 
 }
 
+// Err get the error building the get query, returned by its db functions
+func (q GetQuery) Err() error {
+	if q.all {
+		return q.err
+	}
+	return q.whereErr()
+}
+
+// Compile generate the query with the positional parameters of its dialect
+func (q GetQuery) Compile() CompiledQuery {
+	return q.compile(OpSelect, q.String(), q.Err())
+}
+
+// Named name the get query, passed to middleware
+func (q GetQuery) Named(name string) GetQuery {
+	nq := q
+	nq.query = nq.query.named(name)
+	return nq
+}
+
+// Use attach middleware to the db functions of the get query
+func (q GetQuery) Use(mw ...Middleware) GetQuery {
+	nq := q
+	nq.query = nq.query.use(mw...)
+	return nq
+}
+
 // FnSelect generate the get query as a function to select multiple rows from a DB
 func (q GetQuery) FnSelect() func(tx SelectQuerier, i interface{}, args ...interface{}) error {
 	qs := q.String()
+	lists := q.listExpander(qs)
+	buildErr := q.Err()
 	return func(tx SelectQuerier, i interface{}, args ...interface{}) error {
-		return tx.Select(qs, i, args...)
+		if buildErr != nil {
+			return buildErr
+		}
+		qs, args, err := lists.expand(qs, args)
+		if err != nil {
+			return err
+		}
+		_, err = q.run(context.Background(), OpSelect, qs, args, func(ctx context.Context) (Result, error) {
+			err := tx.Select(qs, i, args...)
+			return Result{Rows: selectedRows(i)}, err
+		})
+		return err
 	}
 }
 
 // FnSelectOne generate the get query as a function to select a single row from a DB
 func (q GetQuery) FnSelectOne() func(tx SelectOneQuerier, i interface{}, args ...interface{}) error {
 	qs := q.String()
+	lists := q.listExpander(qs)
+	buildErr := q.Err()
 	return func(tx SelectOneQuerier, i interface{}, args ...interface{}) error {
-		return tx.SelectOne(qs, i, args...)
+		if buildErr != nil {
+			return buildErr
+		}
+		qs, args, err := lists.expand(qs, args)
+		if err != nil {
+			return err
+		}
+		_, err = q.run(context.Background(), OpSelectOne, qs, args, func(ctx context.Context) (Result, error) {
+			return oneRow(tx.SelectOne(qs, i, args...))
+		})
+		return err
+	}
+}
+
+// FnSelectContext generate the get query as a function to select multiple
+// rows from a DB, honouring the context
+func (q GetQuery) FnSelectContext() func(ctx context.Context, tx SelectContextQuerier, i interface{}, args ...interface{}) error {
+	qs := q.String()
+	lists := q.listExpander(qs)
+	buildErr := q.Err()
+	return func(ctx context.Context, tx SelectContextQuerier, i interface{}, args ...interface{}) error {
+		if buildErr != nil {
+			return buildErr
+		}
+		qs, args, err := lists.expand(qs, args)
+		if err != nil {
+			return err
+		}
+		_, err = q.run(ctx, OpSelect, qs, args, func(ctx context.Context) (Result, error) {
+			err := tx.SelectContext(ctx, qs, i, args...)
+			return Result{Rows: selectedRows(i)}, err
+		})
+		return err
+	}
+}
+
+// FnSelectOneContext generate the get query as a function to select a single
+// row from a DB, honouring the context
+func (q GetQuery) FnSelectOneContext() func(ctx context.Context, tx SelectOneContextQuerier, i interface{}, args ...interface{}) error {
+	qs := q.String()
+	lists := q.listExpander(qs)
+	buildErr := q.Err()
+	return func(ctx context.Context, tx SelectOneContextQuerier, i interface{}, args ...interface{}) error {
+		if buildErr != nil {
+			return buildErr
+		}
+		qs, args, err := lists.expand(qs, args)
+		if err != nil {
+			return err
+		}
+		_, err = q.run(ctx, OpSelectOne, qs, args, func(ctx context.Context) (Result, error) {
+			return oneRow(tx.SelectOneContext(ctx, qs, i, args...))
+		})
+		return err
 	}
 }
 
 // GetQueryOptions optional arguments to create a new get query
 type GetQueryOptions struct {
 	MakeQuery func(args MakeGetQueryArgs) string
+	Dialect   Dialect
+	Quote     QuoteMode
 }
 
 // NewGet generate a new get query
 func NewGet(tableName string, i interface{}, opts ...GetQueryOptions) GetQuery {
-	tags := getTagsByName("db", i)
+	fields, err := getFieldsByTag("db", i)
 
 	var options GetQueryOptions
 	if len(opts) > 0 {
 		options = opts[0]
 	}
 
+	quoter := Quoter{
+		Dialect: dialectOrDefault(options.Dialect),
+		Mode:    options.Quote,
+	}
+	primaryKey, primaryKeyErr := primaryKeyOf(tableName, fields, quoter)
+
 	q := GetQuery{
 		query: query{
 			tableName: tableName,
+			columns:   columnsOf(fields, nil),
+			err:       err,
+			dialect:   dialectOrDefault(options.Dialect),
+			quoter:    quoter,
 			returnFields: Columns{
 				TableName: tableName,
-				Fields:    tags,
+				Fields:    columnsOf(fields, field.returnable),
+				Quoter:    quoter,
 			},
-			whereClause: DefaultIdentityString,
+			primaryKey:    primaryKey,
+			primaryKeyErr: primaryKeyErr,
 		},
 
-		makeQuery: func(args MakeGetQueryArgs) string {
-			return fmt.Sprintf(
-				templSelect,
-				args.ReturnFields.AsSelects().Joined(),
-				args.TableName,
-				args.WhereClause,
-			)
-		},
+		makeQuery: makeGetQuery,
 	}
 
 	if options.MakeQuery != nil {
@@ -109,3 +344,77 @@ func NewGet(tableName string, i interface{}, opts ...GetQueryOptions) GetQuery {
 	return q
 
 }
+
+// makeGetQuery render a get query for the dialect of the args
+func makeGetQuery(args MakeGetQueryArgs) string {
+	var b strings.Builder
+	if args.WhereClause == "" {
+		fmt.Fprintf(
+			&b,
+			templSelectAll,
+			args.ReturnFields.AsSelects().Joined(),
+			args.Quoter.Ident(args.TableName),
+		)
+	} else {
+		fmt.Fprintf(
+			&b,
+			templSelect,
+			args.ReturnFields.AsSelects().Joined(),
+			args.Quoter.Ident(args.TableName),
+			args.WhereClause,
+		)
+	}
+
+	var order []string
+	for _, term := range args.OrderBy {
+		order = append(order, args.Quoter.Ident(term.Column)+" "+string(term.Direction))
+	}
+
+	// SQL Server pages rows with OFFSET ... FETCH, which requires an ORDER BY
+	if dialectOrDefault(args.Dialect).Name() == "sqlserver" {
+		if args.Limit == "" && args.Offset == "" {
+			if len(order) > 0 {
+				fmt.Fprintf(&b, templOrderBy, strings.Join(order, ", "))
+			}
+			return b.String()
+		}
+
+		if len(order) == 0 {
+			order = []string{"(SELECT NULL)"}
+		}
+		offset := args.Offset
+		if offset == "" {
+			offset = "0"
+		}
+
+		fmt.Fprintf(&b, templOrderBy, strings.Join(order, ", "))
+		fmt.Fprintf(&b, templOffsetRows, offset)
+		if args.Limit != "" {
+			fmt.Fprintf(&b, templFetchNext, args.Limit)
+		}
+		return b.String()
+	}
+
+	if len(order) > 0 {
+		fmt.Fprintf(&b, templOrderBy, strings.Join(order, ", "))
+	}
+
+	limit := args.Limit
+	if limit == "" && args.Offset != "" {
+		// MySQL and SQLite only accept an offset after a limit
+		switch dialectOrDefault(args.Dialect).Name() {
+		case "mysql":
+			limit = "18446744073709551615"
+		case "sqlite":
+			limit = "-1"
+		}
+	}
+	if limit != "" {
+		fmt.Fprintf(&b, templLimit, limit)
+	}
+	if args.Offset != "" {
+		fmt.Fprintf(&b, templOffset, args.Offset)
+	}
+
+	return b.String()
+}
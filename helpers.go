@@ -1,9 +1,125 @@
 package dbgen
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 )
 
+// Tag options controlling how a column is used by the query builders,
+// given after the column name as in `db:"id,pk"` or `db:"created_at,insert=-"`
+const (
+	// tagPrimaryKey the column is part of the primary key, it is not updated
+	tagPrimaryKey = "pk"
+	// tagReadOnly the column is generated by the db, it is never written
+	tagReadOnly = "readonly"
+	// tagInsert set to "-" to omit the column from inserted values
+	tagInsert = "insert"
+	// tagUpdate set to "-" to omit the column from updated values
+	tagUpdate = "update"
+	// tagReturn set to "-" to omit the column from selected and returned fields
+	tagReturn = "return"
+	// tagUnique the column has a unique constraint
+	tagUnique = "unique"
+	// tagNotNull the column is not null, inferred for non pointer fields
+	tagNotNull = "notnull"
+	// tagDefault the SQL default value of the column, e.g. default=now()
+	tagDefault = "default"
+	// tagType the SQL type of the column, overriding the inferred type,
+	// e.g. type=numeric(10,2)
+	tagType = "type"
+)
+
+// tagOptions the options given after the column name of a struct tag
+type tagOptions map[string]string
+
+// has whether the option is set
+func (o tagOptions) has(name string) bool {
+	_, ok := o[name]
+	return ok
+}
+
+// omits whether the option is set to "-"
+func (o tagOptions) omits(name string) bool {
+	return o[name] == "-"
+}
+
+// field a struct field mapped to a column
+type field struct {
+	column  string
+	options tagOptions
+	index   []int
+	typ     reflect.Type
+}
+
+// insertable whether the column is written by insert queries
+func (f field) insertable() bool {
+	return !f.options.has(tagReadOnly) && !f.options.omits(tagInsert)
+}
+
+// updatable whether the column is written by update queries
+func (f field) updatable() bool {
+	return !f.options.has(tagReadOnly) &&
+		!f.options.has(tagPrimaryKey) &&
+		!f.options.omits(tagUpdate)
+}
+
+// upsertable whether the column is updated by upsert queries, updates are
+// taken from the conflicting inserted row so the column must be inserted too
+func (f field) upsertable() bool {
+	return f.insertable() && f.updatable()
+}
+
+// returnable whether the column is selected and returned by queries
+func (f field) returnable() bool {
+	return !f.options.omits(tagReturn)
+}
+
+// parseTag split a struct tag into its column name and options
+func parseTag(tag string) (string, tagOptions) {
+	parts := splitTag(tag)
+	options := tagOptions{}
+
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value := part, ""
+		if eq := strings.Index(part, "="); eq >= 0 {
+			name, value = part[:eq], part[eq+1:]
+		}
+		options[name] = value
+	}
+
+	return strings.TrimSpace(parts[0]), options
+}
+
+// splitTag split a struct tag at its commas, except for those between
+// parentheses or single quotes such as in type=numeric(10,2)
+func splitTag(tag string) []string {
+	var parts []string
+	depth, quoted, start := 0, false, 0
+
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, tag[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, tag[start:])
+}
+
 func filterTags(tags []string, tagsToOmit []string) []string {
 	var tagsFiltered []string
 
@@ -25,16 +141,133 @@ func filterTags(tags []string, tagsToOmit []string) []string {
 
 }
 
-func getTagsByName(tagName string, i interface{}) []string {
-	t := reflect.TypeOf(i)
-	var tagValues []string
+// columnsOf get the columns of the fields accepted by include, every field
+// if include is nil
+func columnsOf(fields []field, include func(f field) bool) []string {
+	var columns []string
+	for _, f := range fields {
+		if include == nil || include(f) {
+			columns = append(columns, f.column)
+		}
+	}
+	return columns
+}
+
+// getFieldsByTag get the fields of a struct, or pointer to a struct, mapped
+// to columns by their tag. Anonymous embedded structs are flattened and
+// nested structs tagged with a name ending in "_" are flattened with their
+// tag as a prefix, inheriting the options of the nested struct tag.
+func getFieldsByTag(tagName string, i interface{}) ([]field, error) {
+	t := indirectType(reflect.TypeOf(i))
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("dbgen: expected a struct or pointer to a struct, got %T", i)
+	}
+
+	return getFieldsOfType(tagName, t, "", nil, nil), nil
+}
+
+func getFieldsOfType(
+	tagName string,
+	t reflect.Type,
+	prefix string,
+	parentOptions tagOptions,
+	parentIndex []int,
+) []field {
+	var fields []field
 
 	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		tag := field.Tag.Get(tagName)
-		if tag != "" {
-			tagValues = append(tagValues, tag)
+		structField := t.Field(i)
+		column, options := parseTag(structField.Tag.Get(tagName))
+		if column == "-" {
+			continue
+		}
+
+		for name, value := range parentOptions {
+			if !options.has(name) {
+				options[name] = value
+			}
 		}
+
+		index := append(append([]int{}, parentIndex...), i)
+		fieldType := indirectType(structField.Type)
+
+		switch {
+		case structField.Anonymous && column == "" && fieldType.Kind() == reflect.Struct:
+			fields = append(
+				fields,
+				getFieldsOfType(tagName, fieldType, prefix, options, index)...,
+			)
+
+		case strings.HasSuffix(column, "_") && fieldType.Kind() == reflect.Struct:
+			fields = append(
+				fields,
+				getFieldsOfType(tagName, fieldType, prefix+column, options, index)...,
+			)
+
+		case column != "" && structField.PkgPath == "":
+			fields = append(fields, field{
+				column:  prefix + column,
+				options: options,
+				index:   index,
+				typ:     structField.Type,
+			})
+		}
+	}
+	return fields
+}
+
+func getTagsByName(tagName string, i interface{}) ([]string, error) {
+	fields, err := getFieldsByTag(tagName, i)
+	return columnsOf(fields, func(f field) bool {
+		return true
+	}), err
+}
+
+func getValuesByTag(tagName string, i interface{}) (map[string]interface{}, error) {
+	v := reflect.ValueOf(i)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("dbgen: expected a struct or pointer to a struct, got %T", i)
+	}
+
+	fields, err := getFieldsByTag(tagName, i)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		values[f.column] = nil
+		if fv, ok := fieldByIndex(v, f.index); ok {
+			values[f.column] = fv.Interface()
+		}
+	}
+	return values, nil
+}
+
+// fieldByIndex get the nested field of a struct value, false if the field
+// is reached through a nil pointer
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			for v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return reflect.Value{}, false
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// indirectType get the type pointed to by any number of pointers
+func indirectType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
 	}
-	return tagValues
+	return t
 }
@@ -1,23 +1,60 @@
 package dbgen
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxBindParams the maximum number of bind parameters a bulk insert
+// statement may use with dialects that are not a BatchLimiter, matching the
+// Postgres protocol limit
+const DefaultMaxBindParams = 65535
 
 // InsertQuerier interface required to build an insert db function
 type InsertQuerier interface {
 	Insert(q string, val interface{}) error
 }
 
-// MakeUpdateQueryArgs arguments required to make an update query
+// InsertContextQuerier interface required to build a context aware insert
+// db function
+type InsertContextQuerier interface {
+	InsertContext(ctx context.Context, q string, val interface{}) error
+}
+
+// BulkInsertQuerier interface required to build a bulk insert db function
+type BulkInsertQuerier interface {
+	InsertMany(q string, args map[string]interface{}) error
+}
+
+// BulkInsertContextQuerier interface required to build a context aware bulk
+// insert db function
+type BulkInsertContextQuerier interface {
+	InsertManyContext(ctx context.Context, q string, args map[string]interface{}) error
+}
+
+// MakeInsertQueryArgs arguments required to make an insert query
 type MakeInsertQueryArgs struct {
 	TableName    string
 	Values       Columns
 	ReturnFields Columns
+	Dialect      Dialect
+	Quoter       Quoter
+	// Rows the number of rows to render with indexed parameters and no
+	// returned rows, zero renders a single row with plain parameters. Only
+	// the MakeManyQuery of InsertQueryOptions is given rows, MakeQuery
+	// renders single rows.
+	Rows int
 }
 
 // InsertQuery represents an insert query
 type InsertQuery struct {
-	query     query
-	makeQuery func(args MakeInsertQueryArgs) string
+	query         query
+	maxBindParams int
+	makeQuery     func(args MakeInsertQueryArgs) string
+	makeManyQuery func(args MakeInsertQueryArgs) string
 }
 
 // OmitValues omit value fields to insert from the query
@@ -51,21 +88,229 @@ func (q InsertQuery) String() string {
 			TableName:    q.query.tableName,
 			Values:       q.query.valueFields,
 			ReturnFields: q.query.returnFields,
+			Dialect:      q.query.dialect,
+			Quoter:       q.query.quoter,
+		},
+	)
+}
+
+// MaxBindParams set the maximum number of bind parameters used by each
+// statement of a bulk insert, overriding the limit of the dialect, e.g. 999
+// for SQLite builds older than 3.32
+func (q InsertQuery) MaxBindParams(n int) InsertQuery {
+	iq := q
+	iq.maxBindParams = n
+	return iq
+}
+
+// StringMany generate the query as a string inserting n rows, parameters
+// are suffixed with the index of their row (:name_0, :name_1, ...). Bulk
+// inserts return no rows.
+func (q InsertQuery) StringMany(n int) string {
+	return q.makeManyQuery(
+		MakeInsertQueryArgs{
+			TableName:    q.query.tableName,
+			Values:       q.query.valueFields,
+			ReturnFields: q.query.returnFields,
+			Dialect:      q.query.dialect,
+			Quoter:       q.query.quoter,
+			Rows:         n,
 		},
 	)
 }
 
+// Err get the error building the insert query, returned by its db functions
+func (q InsertQuery) Err() error {
+	return q.query.err
+}
+
+// Compile generate the query with the positional parameters of its dialect
+func (q InsertQuery) Compile() CompiledQuery {
+	return q.query.compile(OpInsert, q.String(), q.query.err)
+}
+
+// CompileMany generate the query inserting n rows with the positional
+// parameters of its dialect
+func (q InsertQuery) CompileMany(n int) CompiledQuery {
+	return q.query.compile(OpInsertMany, q.StringMany(n), q.query.err)
+}
+
+// Named name the insert query, passed to middleware
+func (q InsertQuery) Named(name string) InsertQuery {
+	nq := q
+	nq.query = nq.query.named(name)
+	return nq
+}
+
+// Use attach middleware to the db functions of the insert query
+func (q InsertQuery) Use(mw ...Middleware) InsertQuery {
+	nq := q
+	nq.query = nq.query.use(mw...)
+	return nq
+}
+
 // String generate the query as db function
 func (q InsertQuery) Fn() func(tx InsertQuerier, i interface{}) error {
 	qs := q.String()
 	return func(tx InsertQuerier, i interface{}) error {
-		return tx.Insert(qs, i)
+		if q.query.err != nil {
+			return q.query.err
+		}
+		_, err := q.query.run(context.Background(), OpInsert, qs, []interface{}{i}, func(ctx context.Context) (Result, error) {
+			return oneRow(tx.Insert(qs, i))
+		})
+		return err
+	}
+}
+
+// FnContext generate the query as a db function honouring the context
+func (q InsertQuery) FnContext() func(ctx context.Context, tx InsertContextQuerier, i interface{}) error {
+	qs := q.String()
+	return func(ctx context.Context, tx InsertContextQuerier, i interface{}) error {
+		if q.query.err != nil {
+			return q.query.err
+		}
+		_, err := q.query.run(ctx, OpInsert, qs, []interface{}{i}, func(ctx context.Context) (Result, error) {
+			return oneRow(tx.InsertContext(ctx, qs, i))
+		})
+		return err
+	}
+}
+
+// FnMany generate the query as a db function inserting a slice of values,
+// split into as many statements as the max bind parameter count requires
+func (q InsertQuery) FnMany() func(tx BulkInsertQuerier, vals interface{}) error {
+	insertMany := q.bulkInserter()
+	return func(tx BulkInsertQuerier, vals interface{}) error {
+		return insertMany(context.Background(), vals, func(ctx context.Context, qs string, args map[string]interface{}) error {
+			return tx.InsertMany(qs, args)
+		})
+	}
+}
+
+// FnManyContext generate the query as a db function inserting a slice of
+// values, honouring the context
+func (q InsertQuery) FnManyContext() func(ctx context.Context, tx BulkInsertContextQuerier, vals interface{}) error {
+	insertMany := q.bulkInserter()
+	return func(ctx context.Context, tx BulkInsertContextQuerier, vals interface{}) error {
+		return insertMany(ctx, vals, func(ctx context.Context, qs string, args map[string]interface{}) error {
+			return tx.InsertManyContext(ctx, qs, args)
+		})
 	}
 }
 
+// bulkInserter get a function splitting a slice of values into bulk insert
+// statements, caching the statement for each chunk size. Each statement is
+// run through the middleware of the query.
+func (q InsertQuery) bulkInserter() func(
+	ctx context.Context,
+	vals interface{},
+	insert func(ctx context.Context, qs string, args map[string]interface{}) error,
+) error {
+	chunkSize := q.rowsPerStatement()
+	var statements sync.Map
+
+	return func(
+		ctx context.Context,
+		vals interface{},
+		insert func(ctx context.Context, qs string, args map[string]interface{}) error,
+	) error {
+		if q.query.err != nil {
+			return q.query.err
+		}
+
+		v := reflect.Indirect(reflect.ValueOf(vals))
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return fmt.Errorf("dbgen: bulk insert expects a slice, got %T", vals)
+		}
+
+		for start := 0; start < v.Len(); start += chunkSize {
+			end := start + chunkSize
+			if end > v.Len() {
+				end = v.Len()
+			}
+
+			args, err := q.bulkArgs(v.Slice(start, end))
+			if err != nil {
+				return err
+			}
+
+			qs, ok := statements.Load(end - start)
+			if !ok {
+				qs, _ = statements.LoadOrStore(end-start, q.StringMany(end-start))
+			}
+
+			rows := int64(end - start)
+			_, err = q.query.run(ctx, OpInsertMany, qs.(string), []interface{}{args}, func(ctx context.Context) (Result, error) {
+				if err := insert(ctx, qs.(string), args); err != nil {
+					return Result{Rows: 0}, err
+				}
+				return Result{Rows: rows}, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// rowsPerStatement the number of rows that fit a single bulk insert
+// statement within the limits of the dialect
+func (q InsertQuery) rowsPerStatement() int {
+	maxParams := DefaultMaxBindParams
+	maxRows := 0
+	if l, ok := q.query.dialect.(BatchLimiter); ok {
+		maxParams = l.MaxBindParams()
+		maxRows = l.MaxInsertRows()
+	}
+	if q.maxBindParams > 0 {
+		maxParams = q.maxBindParams
+	}
+
+	n := len(q.query.valueFields.Fields)
+	if n == 0 || maxParams < n {
+		return 1
+	}
+
+	rows := maxParams / n
+	if maxRows > 0 && rows > maxRows {
+		rows = maxRows
+	}
+	return rows
+}
+
+// bulkArgs get the indexed named arguments of a chunk of rows
+func (q InsertQuery) bulkArgs(rows reflect.Value) (map[string]interface{}, error) {
+	args := make(map[string]interface{}, rows.Len()*len(q.query.valueFields.Fields))
+
+	for i := 0; i < rows.Len(); i++ {
+		values, err := getValuesByTag("db", rows.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, field := range q.query.valueFields.Fields {
+			value, ok := values[field]
+			if !ok {
+				return nil, fmt.Errorf("dbgen: row %d has no value for column %s", i, field)
+			}
+			args[fmt.Sprintf("%s_%d", field, i)] = value
+		}
+	}
+
+	return args, nil
+}
+
 // InsertQueryOptions optional arguments to create a new insert query
 type InsertQueryOptions struct {
 	MakeQuery func(args MakeInsertQueryArgs) string
+	// MakeManyQuery render bulk inserts of args.Rows rows, the default bulk
+	// insert unless set, whether or not MakeQuery is set
+	MakeManyQuery func(args MakeInsertQueryArgs) string
+	Dialect       Dialect
+	Quote         QuoteMode
 }
 
 // NewInsert construct a new insert query
@@ -74,42 +319,97 @@ func NewInsert(
 	i interface{},
 	opts ...InsertQueryOptions,
 ) InsertQuery {
-	tags := getTagsByName("db", i)
+	fields, err := getFieldsByTag("db", i)
 
 	var options InsertQueryOptions
 	if len(opts) > 0 {
 		options = opts[0]
 	}
 
+	quoter := Quoter{
+		Dialect: dialectOrDefault(options.Dialect),
+		Mode:    options.Quote,
+	}
+
 	iq := InsertQuery{
 		query: query{
 			tableName: tableName,
+			columns:   columnsOf(fields, nil),
+			err:       err,
+			dialect:   dialectOrDefault(options.Dialect),
+			quoter:    quoter,
 			valueFields: Columns{
 				TableName: tableName,
-				Fields:    tags,
+				Fields:    columnsOf(fields, field.insertable),
+				Quoter:    quoter,
 			},
 			returnFields: Columns{
 				TableName: tableName,
-				Fields:    tags,
+				Fields:    columnsOf(fields, field.returnable),
+				Quoter:    quoter,
 			},
 		},
-		makeQuery: func(
-			args MakeInsertQueryArgs,
-		) string {
-			return fmt.Sprintf(
-				templInsert,
-				args.TableName,
-				args.Values.Joined(),
-				args.Values.AsParams().Joined(),
-				args.ReturnFields.AsSelects().Joined(),
-			)
-		},
+		makeQuery:     makeInsertQuery,
+		makeManyQuery: makeInsertQuery,
 	}
 
 	if options.MakeQuery != nil {
 		iq.makeQuery = options.MakeQuery
 	}
+	if options.MakeManyQuery != nil {
+		iq.makeManyQuery = options.MakeManyQuery
+	}
 
 	return iq
 
 }
+
+// makeInsertQuery render an insert query for the dialect of the args
+func makeInsertQuery(args MakeInsertQueryArgs) string {
+	returning := returningStyle(args.Dialect, args.ReturnFields)
+
+	// the returned rows of a bulk insert cannot be scanned back into its
+	// values, bulk inserts return no rows
+	if args.Rows > 0 {
+		var rows []string
+		for i := 0; i < args.Rows; i++ {
+			rows = append(
+				rows,
+				fmt.Sprintf("(%s)", args.Values.AsIndexedParams(i).Joined()),
+			)
+		}
+
+		return fmt.Sprintf(
+			templInsertMany,
+			args.Quoter.Ident(args.TableName),
+			args.Values.Quoted().Joined(),
+			strings.Join(rows, ",\n\t\t"),
+		)
+	}
+
+	switch returning {
+	case ReturningNone:
+		return fmt.Sprintf(
+			templInsertNoReturn,
+			args.Quoter.Ident(args.TableName),
+			args.Values.Quoted().Joined(),
+			args.Values.AsParams().Joined(),
+		)
+	case ReturningOutput:
+		return fmt.Sprintf(
+			templInsertOutput,
+			args.Quoter.Ident(args.TableName),
+			args.Values.Quoted().Joined(),
+			args.ReturnFields.AsQualified("INSERTED").Joined(),
+			args.Values.AsParams().Joined(),
+		)
+	}
+
+	return fmt.Sprintf(
+		templInsert,
+		args.Quoter.Ident(args.TableName),
+		args.Values.Quoted().Joined(),
+		args.Values.AsParams().Joined(),
+		args.ReturnFields.AsSelects().Joined(),
+	)
+}
@@ -0,0 +1,176 @@
+package dbgen
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// keysetParamPrefix the prefix of the parameters bound to the key columns
+// of the last row of the previous page
+const keysetParamPrefix = "after_"
+
+// ErrInvalidCursor the cursor of a page is malformed or belongs to another
+// query
+var ErrInvalidCursor = errors.New("dbgen: invalid page cursor")
+
+// Keyset order the selected rows by the key columns, which together must be
+// unique, to select them a page at a time. The After query selects the rows
+// following the key columns of the last row of a page, e.g.
+// (created_at, id) > (:after_created_at, :after_id). Rows are selected from
+// every row of the table unless a where clause is set.
+func (q GetQuery) Keyset(direction SortDirection, columns ...string) GetQuery {
+	nq := q
+	nq.all = true
+	nq.keyset = append([]string{}, columns...)
+	nq.orderBy = nil
+	for _, column := range columns {
+		nq = nq.OrderBy(column, direction)
+	}
+
+	if nq.err == nil && len(columns) == 0 {
+		nq.err = errors.New("dbgen: keyset without columns")
+	}
+	return nq
+}
+
+// After the keyset query selecting the rows after the key columns of the
+// last row of a page, bound to the parameters after_<column>
+func (q GetQuery) After() GetQuery {
+	nq := q
+	if len(q.keyset) == 0 {
+		if nq.err == nil {
+			nq.err = errors.New("dbgen: After requires a Keyset")
+		}
+		return nq
+	}
+
+	after := keysetPredicate{
+		columns:   q.keyset,
+		direction: q.orderBy[0].Direction,
+	}
+
+	if q.whereClause == "" {
+		return nq.Where(after)
+	}
+	return nq.Where(And(Raw(q.whereClause), after))
+}
+
+// keysetPredicate the rows after the key columns of a row
+type keysetPredicate struct {
+	columns   []string
+	direction SortDirection
+}
+
+func (k keysetPredicate) renderPredicate(q query) (string, error) {
+	op := ">"
+	if k.direction == Desc {
+		op = "<"
+	}
+
+	columns := make([]string, len(k.columns))
+	params := make([]string, len(k.columns))
+	for i, column := range k.columns {
+		if err := q.checkColumn(column); err != nil {
+			return "", err
+		}
+		columns[i] = q.quoter.Ident(column)
+		params[i] = ":" + keysetParamPrefix + column
+	}
+
+	if len(columns) == 1 {
+		return columns[0] + op + params[0], nil
+	}
+
+	// SQL Server has no row value comparisons, compare column by column
+	if dialectOrDefault(q.dialect).Name() == "sqlserver" {
+		var terms []string
+		for i := range columns {
+			var term []string
+			for j := 0; j < i; j++ {
+				term = append(term, columns[j]+"="+params[j])
+			}
+			term = append(term, columns[i]+op+params[i])
+			terms = append(terms, strings.Join(term, " AND "))
+		}
+		return "(" + strings.Join(terms, ") OR (") + ")", nil
+	}
+
+	return fmt.Sprintf(
+		"(%s) %s (%s)",
+		strings.Join(columns, ", "), op, strings.Join(params, ", "),
+	), nil
+}
+
+// cursor the encoded key columns of the last row of a page
+type cursor struct {
+	Columns []string          `json:"k"`
+	Values  []json.RawMessage `json:"v"`
+}
+
+// encodeCursor encode the key columns of a row as an opaque cursor
+func encodeCursor(columns []string, row interface{}) (string, error) {
+	values, err := getValuesByTag("db", row)
+	if err != nil {
+		return "", err
+	}
+
+	c := cursor{Columns: columns}
+	for _, column := range columns {
+		value, err := json.Marshal(values[column])
+		if err != nil {
+			return "", err
+		}
+		c.Values = append(c.Values, value)
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCursor decode the key columns of a cursor into the after_<column>
+// parameters, typed as the fields of the struct i
+func decodeCursor(columns []string, encoded string, i interface{}) (map[string]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil ||
+		strings.Join(c.Columns, ",") != strings.Join(columns, ",") ||
+		len(c.Values) != len(columns) {
+		return nil, ErrInvalidCursor
+	}
+
+	fields, err := getFieldsByTag("db", i)
+	if err != nil {
+		return nil, err
+	}
+	types := map[string]reflect.Type{}
+	for _, f := range fields {
+		types[f.column] = f.typ
+	}
+
+	params := make(map[string]interface{}, len(columns))
+	for n, column := range columns {
+		t, ok := types[column]
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+
+		value := reflect.New(t)
+		if err := json.Unmarshal(c.Values[n], value.Interface()); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		params[keysetParamPrefix+column] = value.Elem().Interface()
+	}
+
+	return params, nil
+}
@@ -0,0 +1,191 @@
+package dbgen
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Operation the kind of statement run by a db function
+type Operation string
+
+// Operations run by the db functions of each query
+const (
+	OpSelect      Operation = "select"
+	OpSelectOne   Operation = "select_one"
+	OpInsert      Operation = "insert"
+	OpInsertMany  Operation = "insert_many"
+	OpUpdate      Operation = "update"
+	OpUpsert      Operation = "upsert"
+	OpDelete      Operation = "delete"
+	OpCreateTable Operation = "create_table"
+	OpCount       Operation = "count"
+	OpExists      Operation = "exists"
+	// OpExec run by the FnExec functions of queries compiled with Compile
+	OpExec Operation = "exec"
+)
+
+// Statement the SQL statement run by the operation, shared by the metrics
+// and tracing of db functions: select for gets, counts and exists checks,
+// insert for inserts and upserts
+func (op Operation) Statement() Operation {
+	switch op {
+	case OpSelectOne, OpCount, OpExists:
+		return OpSelect
+	case OpInsertMany, OpUpsert:
+		return OpInsert
+	}
+	return op
+}
+
+// QueryInfo describes a query run by a db function
+type QueryInfo struct {
+	// Name the name given to the query with Named, empty if unnamed
+	Name      string
+	Table     string
+	Operation Operation
+	Dialect   Dialect
+	SQL       string
+	// Args the arguments of the query, the struct or bulk insert map for
+	// writes
+	Args []interface{}
+}
+
+// Result the outcome of a query run by a db function
+type Result struct {
+	// Rows the number of rows returned or affected, -1 if the querier does
+	// not report it
+	Rows int64
+}
+
+// Exec run a query described by info
+type Exec func(ctx context.Context, info QueryInfo) (Result, error)
+
+// Middleware wrap the execution of queries, e.g. to log, time or trace them
+type Middleware func(next Exec) Exec
+
+// Hooks functions run before and after every query, usable as middleware
+type Hooks struct {
+	// Before run before the query, the returned context is passed to the
+	// querier and After
+	Before func(ctx context.Context, info QueryInfo) context.Context
+	After  func(ctx context.Context, info QueryInfo, res Result, err error, elapsed time.Duration)
+}
+
+// Middleware get the hooks as middleware
+func (h Hooks) Middleware() Middleware {
+	return func(next Exec) Exec {
+		return func(ctx context.Context, info QueryInfo) (Result, error) {
+			if h.Before != nil {
+				ctx = h.Before(ctx, info)
+			}
+
+			start := time.Now()
+			res, err := next(ctx, info)
+
+			if h.After != nil {
+				h.After(ctx, info, res, err, time.Since(start))
+			}
+			return res, err
+		}
+	}
+}
+
+var global struct {
+	sync.RWMutex
+	middleware []Middleware
+}
+
+// Use attach middleware to the queries of every db function, run before the
+// middleware attached to each query
+func Use(mw ...Middleware) {
+	global.Lock()
+	defer global.Unlock()
+	global.middleware = append(global.middleware, mw...)
+}
+
+// middlewareOf get the global middleware followed by the query middleware
+func middlewareOf(q query) []Middleware {
+	global.RLock()
+	defer global.RUnlock()
+
+	if len(global.middleware) == 0 {
+		return q.middleware
+	}
+
+	mw := make([]Middleware, 0, len(global.middleware)+len(q.middleware))
+	mw = append(mw, global.middleware...)
+	return append(mw, q.middleware...)
+}
+
+// run run a query of the db function of q through its middleware, the first
+// middleware being the outermost
+func (q query) run(
+	ctx context.Context,
+	op Operation,
+	qs string,
+	args []interface{},
+	exec func(ctx context.Context) (Result, error),
+) (Result, error) {
+	mw := middlewareOf(q)
+	if len(mw) == 0 {
+		return exec(ctx)
+	}
+
+	next := Exec(func(ctx context.Context, info QueryInfo) (Result, error) {
+		return exec(ctx)
+	})
+	for i := len(mw) - 1; i >= 0; i-- {
+		next = mw[i](next)
+	}
+
+	return next(ctx, QueryInfo{
+		Name:      q.name,
+		Table:     q.tableName,
+		Operation: op,
+		Dialect:   q.dialect,
+		SQL:       qs,
+		Args:      args,
+	})
+}
+
+func (q query) named(name string) query {
+	q2 := q
+	q2.name = name
+	return q2
+}
+
+func (q query) use(mw ...Middleware) query {
+	q2 := q
+	q2.middleware = append(q.middleware[:len(q.middleware):len(q.middleware)], mw...)
+	return q2
+}
+
+// selectedRows the number of rows selected into dest, -1 unless dest is a
+// pointer to a slice
+func selectedRows(dest interface{}) int64 {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return -1
+	}
+	return int64(v.Elem().Len())
+}
+
+// oneRow the result of a query writing or selecting a single row
+func oneRow(err error) (Result, error) {
+	if err != nil {
+		return Result{Rows: 0}, err
+	}
+	return Result{Rows: 1}, nil
+}
+
+// unknownRows the result of a query whose querier does not report rows
+func unknownRows(err error) (Result, error) {
+	return Result{Rows: -1}, err
+}
+
+// execRows the result of a query returning the number of rows it affected
+func execRows(n int64, err error) (Result, error) {
+	return Result{Rows: n}, err
+}
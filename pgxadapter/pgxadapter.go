@@ -0,0 +1,425 @@
+// Package pgxadapter adapts pgx connections to the dbgen querier interfaces
+// without going through database/sql.
+//
+// Queries are rendered with $n positional parameters, or with @name
+// parameters bound through pgx.NamedArgs. Rows are scanned into the same
+// structs used to build the queries by dbgen rather than
+// pgx.RowToStructByName, which maps neither prefixed nested structs
+// (db:"address_") nor structs with columns omitted from the query by
+// OmitReturns. Columns are matched to fields by db tag exactly as the
+// database/sql and sqlx adapters do.
+package pgxadapter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Conn the query methods shared by *pgxpool.Pool, *pgx.Conn and pgx.Tx
+type Conn interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// Options optional arguments to create a new adapter
+type Options struct {
+	// NamedArgs render @name parameters bound with pgx.NamedArgs instead of
+	// $n positional parameters
+	NamedArgs bool
+}
+
+// Adapter a pgx connection satisfying the dbgen querier interfaces
+type Adapter struct {
+	conn     Conn
+	dialect  dbgen.Dialect
+	compiled *sync.Map
+}
+
+var _ dbgen.TxBeginner = (*Adapter)(nil)
+
+// New adapt a *pgxpool.Pool, *pgx.Conn or pgx.Tx
+func New(conn Conn, opts ...Options) *Adapter {
+	var options Options
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	dialect := dbgen.Postgres
+	if options.NamedArgs {
+		dialect = namedArgs{dbgen.Postgres}
+	}
+
+	return &Adapter{
+		conn:     conn,
+		dialect:  dialect,
+		compiled: &sync.Map{},
+	}
+}
+
+// BeginTx begin a transaction on a *pgxpool.Pool or *pgx.Conn, satisfying
+// dbgen.TxBeginner
+func (a *Adapter) BeginTx(ctx context.Context, opts dbgen.TxOptions) (dbgen.TxCommitter, error) {
+	beginner, ok := a.conn.(interface {
+		BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+	})
+	if !ok {
+		return nil, errors.New("pgxadapter: connection cannot begin a transaction")
+	}
+
+	txOpts, err := txOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := beginner.BeginTx(ctx, txOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txAdapter{
+		Adapter: &Adapter{conn: tx, dialect: a.dialect, compiled: a.compiled},
+		tx:      tx,
+	}, nil
+}
+
+// txAdapter a pgx.Tx satisfying dbgen.TxCommitter
+type txAdapter struct {
+	*Adapter
+	tx pgx.Tx
+}
+
+func (t *txAdapter) Commit(ctx context.Context) error   { return t.tx.Commit(ctx) }
+func (t *txAdapter) Rollback(ctx context.Context) error { return t.tx.Rollback(ctx) }
+
+// txOptions map the isolation level and access mode of a transaction to pgx
+func txOptions(opts dbgen.TxOptions) (pgx.TxOptions, error) {
+	var txOpts pgx.TxOptions
+
+	switch opts.Isolation {
+	case sql.LevelDefault:
+	case sql.LevelReadUncommitted:
+		txOpts.IsoLevel = pgx.ReadUncommitted
+	case sql.LevelReadCommitted:
+		txOpts.IsoLevel = pgx.ReadCommitted
+	case sql.LevelRepeatableRead, sql.LevelSnapshot:
+		txOpts.IsoLevel = pgx.RepeatableRead
+	case sql.LevelSerializable, sql.LevelLinearizable:
+		txOpts.IsoLevel = pgx.Serializable
+	default:
+		return txOpts, fmt.Errorf("pgxadapter: unsupported isolation level %v", opts.Isolation)
+	}
+
+	if opts.ReadOnly {
+		txOpts.AccessMode = pgx.ReadOnly
+	}
+
+	return txOpts, nil
+}
+
+// Select select rows into dest, a pointer to a slice
+func (a *Adapter) Select(query string, dest interface{}, args ...interface{}) error {
+	return a.SelectContext(context.Background(), query, dest, args...)
+}
+
+// SelectContext select rows into dest, a pointer to a slice
+func (a *Adapter) SelectContext(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
+	qs, bound, err := a.bindArgs(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return a.QueryPositionalContext(ctx, qs, dest, bound...)
+}
+
+// SelectOne select a single row into dest, pgx.ErrNoRows if there is none
+func (a *Adapter) SelectOne(query string, dest interface{}, args ...interface{}) error {
+	return a.SelectOneContext(context.Background(), query, dest, args...)
+}
+
+// SelectOneContext select a single row into dest, pgx.ErrNoRows if there is none
+func (a *Adapter) SelectOneContext(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
+	qs, bound, err := a.bindArgs(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return a.queryOne(ctx, qs, dest, bound...)
+}
+
+// SelectScalar select a single column of a single row into dest, such as
+// the result of a count or exists query
+func (a *Adapter) SelectScalar(query string, dest interface{}, args ...interface{}) error {
+	return a.SelectOneContext(context.Background(), query, dest, args...)
+}
+
+// SelectScalarContext select a single column of a single row into dest,
+// such as the result of a count or exists query
+func (a *Adapter) SelectScalarContext(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
+	return a.SelectOneContext(ctx, query, dest, args...)
+}
+
+// Insert insert val, scanning returned columns back into val
+func (a *Adapter) Insert(query string, val interface{}) error {
+	return a.InsertContext(context.Background(), query, val)
+}
+
+// InsertContext insert val, scanning returned columns back into val
+func (a *Adapter) InsertContext(ctx context.Context, query string, val interface{}) error {
+	return a.write(ctx, query, val)
+}
+
+// InsertMany insert rows bound from the indexed arguments of a bulk insert
+func (a *Adapter) InsertMany(query string, args map[string]interface{}) error {
+	return a.InsertManyContext(context.Background(), query, args)
+}
+
+// InsertManyContext insert rows bound from the indexed arguments of a bulk insert
+func (a *Adapter) InsertManyContext(ctx context.Context, query string, args map[string]interface{}) error {
+	qs, bound, err := a.bind(query, args)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.conn.Exec(ctx, qs, bound...)
+	return err
+}
+
+// Update update the row of val, scanning returned columns back into val
+func (a *Adapter) Update(query string, val interface{}) error {
+	return a.UpdateContext(context.Background(), query, val)
+}
+
+// UpdateContext update the row of val, scanning returned columns back into val
+func (a *Adapter) UpdateContext(ctx context.Context, query string, val interface{}) error {
+	return a.write(ctx, query, val)
+}
+
+// Upsert upsert val, scanning returned columns back into val
+func (a *Adapter) Upsert(query string, val interface{}) error {
+	return a.UpsertContext(context.Background(), query, val)
+}
+
+// UpsertContext upsert val, scanning returned columns back into val
+func (a *Adapter) UpsertContext(ctx context.Context, query string, val interface{}) error {
+	return a.write(ctx, query, val)
+}
+
+// Delete delete rows, returning the number of rows affected
+func (a *Adapter) Delete(query string, args ...interface{}) (int64, error) {
+	return a.DeleteContext(context.Background(), query, args...)
+}
+
+// DeleteContext delete rows, returning the number of rows affected
+func (a *Adapter) DeleteContext(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	qs, bound, err := a.bindArgs(query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return a.ExecPositionalContext(ctx, qs, bound...)
+}
+
+// QueryPositional run a compiled query, scanning rows into dest
+func (a *Adapter) QueryPositional(query string, dest interface{}, args ...interface{}) error {
+	return a.QueryPositionalContext(context.Background(), query, dest, args...)
+}
+
+// QueryPositionalContext run a compiled query, scanning rows into dest, a
+// pointer to a slice for every row or any other pointer for a single row
+func (a *Adapter) QueryPositionalContext(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
+	if !isSlicePtr(dest) {
+		return a.queryOne(ctx, query, dest, args...)
+	}
+
+	rows, err := a.conn.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	_, err = dbgen.ScanAll(rows, columnNames(rows), dest)
+	return err
+}
+
+// ExecPositional run a compiled query, returning the number of rows affected
+func (a *Adapter) ExecPositional(query string, args ...interface{}) (int64, error) {
+	return a.ExecPositionalContext(context.Background(), query, args...)
+}
+
+// ExecPositionalContext run a compiled query, returning the number of rows affected
+func (a *Adapter) ExecPositionalContext(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	tag, err := a.conn.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// Select select rows of T with a dbgen query, scanned by rowToStruct
+func Select[T any](ctx context.Context, a *Adapter, query string, args ...interface{}) ([]T, error) {
+	qs, bound, err := a.bindArgs(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := a.conn.Query(ctx, qs, bound...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, rowToStruct[T])
+}
+
+// Get select a single row of T with a dbgen query, scanned by rowToStruct,
+// pgx.ErrNoRows if there is none
+func Get[T any](ctx context.Context, a *Adapter, query string, args ...interface{}) (T, error) {
+	qs, bound, err := a.bindArgs(query, args...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	rows, err := a.conn.Query(ctx, qs, bound...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return pgx.CollectOneRow(rows, rowToStruct[T])
+}
+
+// rowToStruct a pgx.RowToFunc scanning a row into a T by db tag with dbgen,
+// mapping prefixed nested structs like the queries built from T
+func rowToStruct[T any](row pgx.CollectableRow) (T, error) {
+	var value T
+	targets, err := dbgen.ScanTargets(&value, columnNames(row))
+	if err != nil {
+		return value, err
+	}
+
+	err = row.Scan(targets...)
+	return value, err
+}
+
+// write run an insert or update bound from val, scanning the first returned
+// row, if any, back into val
+func (a *Adapter) write(ctx context.Context, query string, val interface{}) error {
+	qs, bound, err := a.bind(query, val)
+	if err != nil {
+		return err
+	}
+
+	rows, err := a.conn.Query(ctx, qs, bound...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if reflect.ValueOf(val).Kind() == reflect.Ptr {
+		if _, err := dbgen.ScanOne(rows, columnNames(rows), val); err != nil {
+			return err
+		}
+	}
+
+	rows.Close()
+	return rows.Err()
+}
+
+func (a *Adapter) queryOne(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
+	rows, err := a.conn.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found, err := dbgen.ScanOne(rows, columnNames(rows), dest)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return pgx.ErrNoRows
+	}
+
+	rows.Close()
+	return rows.Err()
+}
+
+// bind bind a named query from the fields of a struct or a map
+func (a *Adapter) bind(query string, arg interface{}) (string, []interface{}, error) {
+	c := a.compile(query)
+	bound, err := c.Bind(arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return c.SQL, a.args(c, bound), nil
+}
+
+// bindArgs bind a named query from the arguments of a db function
+func (a *Adapter) bindArgs(query string, args ...interface{}) (string, []interface{}, error) {
+	c := a.compile(query)
+	bound, err := c.BindArgs(args...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return c.SQL, a.args(c, bound), nil
+}
+
+// args get the arguments passed to pgx, as pgx.NamedArgs when rendering
+// named parameters
+func (a *Adapter) args(c dbgen.CompiledQuery, bound []interface{}) []interface{} {
+	if _, ok := a.dialect.(namedArgs); !ok || len(c.Params) == 0 {
+		return bound
+	}
+
+	named := make(pgx.NamedArgs, len(c.Params))
+	for i, name := range c.Params {
+		named[name] = bound[i]
+	}
+	return []interface{}{named}
+}
+
+// compile compile a named query to the parameters of the adapter, caching it
+func (a *Adapter) compile(query string) dbgen.CompiledQuery {
+	if c, ok := a.compiled.Load(query); ok {
+		return c.(dbgen.CompiledQuery)
+	}
+
+	c := dbgen.Compile(query, a.dialect)
+	a.compiled.Store(query, c)
+	return c
+}
+
+// namedArgs renders parameters as pgx @name named arguments
+type namedArgs struct {
+	dbgen.Dialect
+}
+
+func (namedArgs) Placeholder(name string, n int) string { return "@" + name }
+
+func columnNames(rows pgx.CollectableRow) []string {
+	fields := rows.FieldDescriptions()
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func isSlicePtr(dest interface{}) bool {
+	t := reflect.TypeOf(dest)
+	return t != nil &&
+		t.Kind() == reflect.Ptr &&
+		t.Elem().Kind() == reflect.Slice &&
+		t.Elem().Elem().Kind() != reflect.Uint8
+}
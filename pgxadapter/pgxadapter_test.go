@@ -0,0 +1,213 @@
+package pgxadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var errQueried = errors.New("queried")
+
+type connRecorder struct {
+	sql  string
+	args []interface{}
+}
+
+func (c *connRecorder) Exec(ctx context.Context, qs string, args ...interface{}) (pgconn.CommandTag, error) {
+	c.sql, c.args = qs, args
+	return pgconn.NewCommandTag("DELETE 1"), nil
+}
+
+func (c *connRecorder) Query(ctx context.Context, qs string, args ...interface{}) (pgx.Rows, error) {
+	c.sql, c.args = qs, args
+	return nil, errQueried
+}
+
+type user struct {
+	ID    string `db:"id,pk"`
+	Email string `db:"email"`
+}
+
+func Test_Adapter(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		opts     Options
+		run      func(a *Adapter) error
+		wantSQL  string
+		wantArgs string
+	}{
+		{
+			name: "positional select",
+			run: func(a *Adapter) error {
+				return dbgen.NewGet("users", user{}).Where("email=:email").FnSelect()(a, &[]user{}, "a@x.com")
+			},
+			wantSQL:  "SELECT users.id, users.email FROM users WHERE email=$1",
+			wantArgs: "[a@x.com]",
+		},
+		{
+			name: "named args update",
+			opts: Options{NamedArgs: true},
+			run: func(a *Adapter) error {
+				return dbgen.NewUpdate("users", user{}).Fn()(a, &user{ID: "1", Email: "b@x.com"})
+			},
+			wantSQL:  fmt.Sprint(dbgen.Compile(dbgen.NewUpdate("users", user{}).String(), namedArgs{dbgen.Postgres}).SQL),
+			wantArgs: "[map[email:b@x.com id:1]]",
+		},
+		{
+			name: "typed get",
+			opts: Options{NamedArgs: true},
+			run: func(a *Adapter) error {
+				_, err := Get[user](context.Background(), a, dbgen.NewGet("users", user{}).String(), user{ID: "2"})
+				return err
+			},
+			wantSQL:  "SELECT users.id, users.email FROM users WHERE id=@id",
+			wantArgs: "[map[id:2]]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			conn := &connRecorder{}
+			if err := tt.run(New(conn, tt.opts)); !errors.Is(err, errQueried) {
+				t.Fatalf("error = %v, want %v", err, errQueried)
+			}
+
+			if conn.sql != tt.wantSQL {
+				t.Errorf("sql = %+v ||  \n want %+v", conn.sql, tt.wantSQL)
+			}
+			if fmt.Sprint(conn.args) != tt.wantArgs {
+				t.Errorf("args = %+v, want %+v", conn.args, tt.wantArgs)
+			}
+		})
+	}
+
+	conn := &connRecorder{}
+	n, err := dbgen.NewDelete("users", user{}).Fn()(New(conn), "3")
+	if err != nil || n != 1 {
+		t.Errorf("Delete = %d, %v", n, err)
+	}
+	if conn.sql != "DELETE FROM users WHERE id=$1" {
+		t.Errorf("Delete sql = %+v", conn.sql)
+	}
+}
+
+// rowsFake pgx rows of fixed values, scanned by assignment. It embeds
+// pgx.Rows so methods added to the interface need no stub.
+type rowsFake struct {
+	pgx.Rows
+	columns []string
+	values  [][]interface{}
+	row     int
+}
+
+func (r *rowsFake) Close()                        {}
+func (r *rowsFake) Err() error                    { return nil }
+func (r *rowsFake) CommandTag() pgconn.CommandTag { return pgconn.NewCommandTag("SELECT") }
+
+func (r *rowsFake) FieldDescriptions() []pgconn.FieldDescription {
+	fields := make([]pgconn.FieldDescription, len(r.columns))
+	for i, c := range r.columns {
+		fields[i].Name = c
+	}
+	return fields
+}
+
+func (r *rowsFake) Next() bool {
+	r.row++
+	return r.row <= len(r.values)
+}
+
+func (r *rowsFake) Scan(dest ...interface{}) error {
+	if len(dest) != len(r.columns) {
+		return fmt.Errorf("scanned %d columns into %d targets", len(r.columns), len(dest))
+	}
+	for i, d := range dest {
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r.values[r.row-1][i]))
+	}
+	return nil
+}
+
+func (r *rowsFake) Values() ([]interface{}, error) { return r.values[r.row-1], nil }
+
+// connFake a connection returning a copy of its rows to every query
+type connFake struct {
+	rows rowsFake
+}
+
+func (c *connFake) Exec(ctx context.Context, qs string, args ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (c *connFake) Query(ctx context.Context, qs string, args ...interface{}) (pgx.Rows, error) {
+	rows := c.rows
+	return &rows, nil
+}
+
+type address struct {
+	Street string `db:"street"`
+	City   string `db:"city"`
+}
+
+type customer struct {
+	ID      string  `db:"id,pk"`
+	Address address `db:"address_"`
+	Note    string  `db:"note"`
+}
+
+func Test_Scan(t *testing.T) {
+
+	ctx := context.Background()
+	want := []customer{
+		{ID: "1", Address: address{Street: "Rue de Rivoli", City: "Paris"}},
+		{ID: "2", Address: address{Street: "Via Roma", City: "Turin"}},
+	}
+
+	// note is omitted from the query and left unset
+	a := New(&connFake{rows: rowsFake{
+		columns: []string{"id", "address_street", "address_city"},
+		values: [][]interface{}{
+			{"1", "Rue de Rivoli", "Paris"},
+			{"2", "Via Roma", "Turin"},
+		},
+	}})
+	get := dbgen.NewGet("customers", customer{}).OmitReturns("note")
+
+	var customers []customer
+	if err := get.All().FnSelectContext()(ctx, a, &customers); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(customers) != fmt.Sprint(want) {
+		t.Errorf("FnSelect = %+v, want %+v", customers, want)
+	}
+
+	var c customer
+	if err := get.FnSelectOneContext()(ctx, a, &c, "1"); err != nil || c != want[0] {
+		t.Errorf("FnSelectOne = %+v, %v, want %+v", c, err, want[0])
+	}
+
+	typed, err := Select[customer](ctx, a, get.All().String())
+	if err != nil || fmt.Sprint(typed) != fmt.Sprint(want) {
+		t.Errorf("Select = %+v, %v, want %+v", typed, err, want)
+	}
+
+	one, err := Get[customer](ctx, a, get.String(), "1")
+	if err != nil || one != want[0] {
+		t.Errorf("Get = %+v, %v, want %+v", one, err, want[0])
+	}
+
+	empty := New(&connFake{rows: rowsFake{columns: []string{"id"}}})
+	if _, err := Get[customer](ctx, empty, get.String(), "3"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("Get error = %v, want %v", err, pgx.ErrNoRows)
+	}
+	if err := get.FnSelectOneContext()(ctx, empty, &c, "3"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("FnSelectOne error = %v, want %v", err, pgx.ErrNoRows)
+	}
+}
@@ -0,0 +1,253 @@
+package dbgen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Predicate a condition of a where clause built from column names, validated
+// against the columns of the query it is passed to
+type Predicate interface {
+	// renderPredicate render the predicate for a query
+	renderPredicate(q query) (string, error)
+}
+
+// comparison a predicate comparing a column with a named parameter
+type comparison struct {
+	column string
+	op     string
+	param  string
+}
+
+func (c comparison) renderPredicate(q query) (string, error) {
+	if err := q.checkColumn(c.column); err != nil {
+		return "", err
+	}
+	if err := checkParam(c.param); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%s:%s", q.quoter.Ident(c.column), c.op, c.param), nil
+}
+
+// newComparison a comparison of a column with a parameter named after the
+// column unless a param name is given
+func newComparison(column string, op string, param []string) comparison {
+	c := comparison{column: column, op: op, param: column}
+	if len(param) > 0 {
+		c.param = param[0]
+	}
+	return c
+}
+
+// Eq the column equals the parameter, named after the column unless param
+// is given
+func Eq(column string, param ...string) Predicate {
+	return newComparison(column, "=", param)
+}
+
+// Ne the column does not equal the parameter
+func Ne(column string, param ...string) Predicate {
+	return newComparison(column, "<>", param)
+}
+
+// Gt the column is greater than the parameter
+func Gt(column string, param ...string) Predicate {
+	return newComparison(column, ">", param)
+}
+
+// Gte the column is greater than or equal to the parameter
+func Gte(column string, param ...string) Predicate {
+	return newComparison(column, ">=", param)
+}
+
+// Lt the column is less than the parameter
+func Lt(column string, param ...string) Predicate {
+	return newComparison(column, "<", param)
+}
+
+// Lte the column is less than or equal to the parameter
+func Lte(column string, param ...string) Predicate {
+	return newComparison(column, "<=", param)
+}
+
+// Like the column matches the pattern of the parameter
+func Like(column string, param ...string) Predicate {
+	return newComparison(column, " LIKE ", param)
+}
+
+// in a predicate matching a column against a list of values
+type in struct {
+	comparison
+}
+
+func (i in) renderPredicate(q query) (string, error) {
+	if err := q.checkColumn(i.column); err != nil {
+		return "", err
+	}
+	if err := checkParam(i.param); err != nil {
+		return "", err
+	}
+	if dialectOrDefault(q.dialect).Name() == "postgres" {
+		return fmt.Sprintf("%s = ANY(:%s)", q.quoter.Ident(i.column), i.param), nil
+	}
+	return fmt.Sprintf("%s IN (:%s)", q.quoter.Ident(i.column), i.param), nil
+}
+
+// In the column is one of the values of the parameter, a slice. Postgres
+// queries render = ANY(:param), binding the slice as an array: pgx binds
+// slices as is, lib/pq needs them wrapped with pq.Array. Other dialects
+// expand the parameter into a parameter per element when the db function is
+// called. An empty slice matches no rows.
+func In(column string, param ...string) Predicate {
+	return in{newComparison(column, "", param)}
+}
+
+// collectListOperands add the quoted columns of the In predicates expanded
+// as lists to operands by parameter
+func collectListOperands(p Predicate, q query, operands map[string][]string) {
+	switch p := p.(type) {
+	case in:
+		if dialectOrDefault(q.dialect).Name() != "postgres" {
+			operands[p.param] = append(operands[p.param], q.quoter.Ident(p.column))
+		}
+	case junction:
+		for _, jp := range p.predicates {
+			collectListOperands(jp, q, operands)
+		}
+	case not:
+		collectListOperands(p.predicate, q, operands)
+	}
+}
+
+// isNull a predicate matching null values of a column
+type isNull struct {
+	column string
+}
+
+func (n isNull) renderPredicate(q query) (string, error) {
+	if err := q.checkColumn(n.column); err != nil {
+		return "", err
+	}
+	return q.quoter.Ident(n.column) + " IS NULL", nil
+}
+
+// IsNull the column is null
+func IsNull(column string) Predicate {
+	return isNull{column: column}
+}
+
+// junction predicates joined by AND or OR
+type junction struct {
+	op         string
+	predicates []Predicate
+}
+
+func (j junction) renderPredicate(q query) (string, error) {
+	if len(j.predicates) == 0 {
+		return "", fmt.Errorf("dbgen: %s without predicates", j.op)
+	}
+	if len(j.predicates) == 1 {
+		return j.predicates[0].renderPredicate(q)
+	}
+
+	parts := make([]string, 0, len(j.predicates))
+	for _, p := range j.predicates {
+		s, err := p.renderPredicate(q)
+		if err != nil {
+			return "", err
+		}
+		if needsParens(p) {
+			s = "(" + s + ")"
+		}
+		parts = append(parts, s)
+	}
+
+	return strings.Join(parts, " "+j.op+" "), nil
+}
+
+// And all the predicates are true
+func And(predicates ...Predicate) Predicate {
+	return junction{op: "AND", predicates: predicates}
+}
+
+// Or any of the predicates is true
+func Or(predicates ...Predicate) Predicate {
+	return junction{op: "OR", predicates: predicates}
+}
+
+// not a negated predicate
+type not struct {
+	predicate Predicate
+}
+
+func (n not) renderPredicate(q query) (string, error) {
+	s, err := n.predicate.renderPredicate(q)
+	if err != nil {
+		return "", err
+	}
+	return "NOT (" + s + ")", nil
+}
+
+// Not the predicate is false
+func Not(predicate Predicate) Predicate {
+	return not{predicate: predicate}
+}
+
+// raw a predicate of raw SQL
+type raw string
+
+func (r raw) renderPredicate(q query) (string, error) {
+	return string(r), nil
+}
+
+// Raw a predicate of raw SQL, rendered as is without validation
+func Raw(sql string) Predicate {
+	return raw(sql)
+}
+
+// needsParens whether a predicate is parenthesized when joined with others
+func needsParens(p Predicate) bool {
+	switch p := p.(type) {
+	case junction:
+		return len(p.predicates) > 1
+	case raw:
+		return true
+	}
+	return false
+}
+
+// checkColumn check a column of a predicate is a column of the query, any
+// column is accepted by queries built without a struct
+func (q query) checkColumn(column string) error {
+	if len(q.columns) == 0 {
+		return nil
+	}
+	for _, c := range q.columns {
+		if c == column {
+			return nil
+		}
+	}
+	return fmt.Errorf("dbgen: unknown column %q in where clause of %s", column, q.tableName)
+}
+
+// checkParam check a parameter name can be bound
+func checkParam(param string) error {
+	if param == "" {
+		return errors.New("dbgen: empty parameter name in where clause")
+	}
+	for i := 0; i < len(param); i++ {
+		if !isParamChar(param[i]) {
+			return fmt.Errorf("dbgen: invalid parameter name %q in where clause", param)
+		}
+	}
+	return nil
+}
+
+// checkNamedParam check a parameter given as :name can be bound
+func checkNamedParam(param string) error {
+	if !strings.HasPrefix(param, ":") {
+		return fmt.Errorf("dbgen: parameter %q must be given as :name", param)
+	}
+	return checkParam(param[1:])
+}
@@ -1,10 +1,37 @@
 package dbgen
 
+import (
+	"fmt"
+	"strings"
+)
+
+// invalidWhereClause the where clause of a query whose predicate failed to
+// render, which no database accepts
+const invalidWhereClause = "(dbgen: invalid where clause)"
+
+// defaultPrimaryKey the primary key of tables whose struct has no pk columns
+const defaultPrimaryKey = "id"
+
 type query struct {
-	tableName    string
+	tableName string
+	// columns every column of the reflected struct, validating predicates
+	columns      []string
 	valueFields  Columns
 	returnFields Columns
+	primaryKey   Columns
 	whereClause  string
+	dialect      Dialect
+	quoter       Quoter
+	name         string
+	middleware   []Middleware
+	// listOperands the operands of the In predicates of the where clause by
+	// parameter, compared to an empty list as 1=0
+	listOperands map[string][]string
+	// err the error building the query, returned by its db functions
+	err error
+	// primaryKeyErr the error matching the primary key by the default where
+	// clause, the struct has neither pk columns nor an id column
+	primaryKeyErr error
 }
 
 func (q query) omitValues(fields ...string) query {
@@ -20,8 +47,107 @@ func (q query) omitReturns(fields ...string) query {
 	return q2
 }
 
-func (q query) where(whereString string) query {
+// where set the where clause to a raw SQL string or a rendered predicate
+func (q query) where(where interface{}) query {
 	q2 := q
-	q2.whereClause = whereString
+
+	switch w := where.(type) {
+	case string:
+		q2.whereClause = w
+		q2.listOperands = nil
+	case Predicate:
+		clause, err := w.renderPredicate(q)
+		if err != nil {
+			// never fall back to the primary key or to every row
+			clause = invalidWhereClause
+		}
+		q2.whereClause = clause
+		q2.listOperands = map[string][]string{}
+		collectListOperands(w, q, q2.listOperands)
+		if q2.err == nil {
+			q2.err = err
+		}
+	default:
+		if q2.err == nil {
+			q2.err = fmt.Errorf("dbgen: where clause must be a string or a Predicate, got %T", where)
+		}
+	}
+
 	return q2
 }
+
+// listExpander get the expander of the list parameters of a string of the
+// query
+func (q query) listExpander(qs string) *listExpander {
+	return newListExpander(qs, q.listOperands)
+}
+
+// compile compile a string of the query to the positional parameters of
+// its dialect, carrying the error building the query and running the db
+// functions of the compiled query as op through the query middleware
+func (q query) compile(op Operation, qs string, err error) CompiledQuery {
+	c := Compile(qs, q.dialect)
+	c.lists = q.listExpander(qs)
+	c.source, c.op = q, op
+	c.Err = err
+	return c
+}
+
+func (q query) setPrimaryKey(fields ...string) query {
+	q2 := q
+	q2.primaryKey = q2.primaryKey.Set(fields...)
+	if len(fields) > 0 {
+		q2.primaryKeyErr = nil
+	}
+	return q2
+}
+
+// whereString get the where clause of the query, matching the primary key
+// of a single row when no where clause is set
+func (q query) whereString() string {
+	if q.whereClause != "" {
+		return q.whereClause
+	}
+	if len(q.primaryKey.Fields) == 0 {
+		return invalidWhereClause
+	}
+	return strings.Join(q.primaryKey.AsAssignments().Fields, " AND ")
+}
+
+// whereErr the error building a query matching its where clause, the
+// missing primary key when the default where clause is used
+func (q query) whereErr() error {
+	if q.err == nil && q.whereClause == "" {
+		return q.primaryKeyErr
+	}
+	return q.err
+}
+
+// primaryKeyOf get the primary key columns of the fields tagged pk, falling
+// back to the default primary key when the struct has an id column or is
+// not given
+func primaryKeyOf(tableName string, fields []field, quoter Quoter) (Columns, error) {
+	pk := columnsOf(fields, func(f field) bool {
+		return f.options.has(tagPrimaryKey)
+	})
+
+	var err error
+	if len(pk) == 0 {
+		pk = []string{defaultPrimaryKey}
+		if len(fields) > 0 && len(columnsOf(fields, func(f field) bool {
+			return f.column == defaultPrimaryKey
+		})) == 0 {
+			pk = nil
+			err = fmt.Errorf(
+				"dbgen: %s has no primary key, tag its key columns pk or set a where clause",
+				tableName,
+			)
+		}
+	}
+
+	return Columns{
+		TableName: tableName,
+		Fields:    pk,
+		Quoter:    quoter,
+	}, err
+}
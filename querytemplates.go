@@ -1,11 +1,27 @@
 package dbgen
 
 var (
+	// Deprecated: DefaultIdentityString is no longer used, the default where
+	// clause of a query matches the primary key of its struct, set with the
+	// pk tag option or the PrimaryKey method of the query.
 	DefaultIdentityString = "id=:id"
 )
 
 const (
-	templSelect = `SELECT %s FROM %s WHERE %s`
+	templSelect    = `SELECT %s FROM %s WHERE %s`
+	templSelectAll = `SELECT %s FROM %s`
+
+	templOrderBy    = ` ORDER BY %s`
+	templLimit      = ` LIMIT %s`
+	templOffset     = ` OFFSET %s`
+	templOffsetRows = ` OFFSET %s ROWS`
+	templFetchNext  = ` FETCH NEXT %s ROWS ONLY`
+
+	templWhere           = ` WHERE %s`
+	templCount           = `SELECT COUNT(*) FROM %s`
+	templSelectOne       = `SELECT 1 FROM %s`
+	templExists          = `SELECT EXISTS (%s)`
+	templExistsSQLServer = `SELECT CASE WHEN EXISTS (%s) THEN 1 ELSE 0 END`
 
 	templInsert = `INSERT INTO %s (
 		%s
@@ -14,11 +30,91 @@ const (
 	)
 	RETURNING %s`
 
+	templInsertOutput = `INSERT INTO %s (
+		%s
+	)
+	OUTPUT %s
+	VALUES (
+		%s
+	)`
+
+	templInsertNoReturn = `INSERT INTO %s (
+		%s
+	) VALUES (
+		%s
+	)`
+
+	templInsertMany = `INSERT INTO %s (
+		%s
+	) VALUES
+		%s`
+
 	templUpdate = `UPDATE %s
 	SET
 		%s
 	WHERE %s
 	RETURNING %s`
 
+	templUpdateOutput = `UPDATE %s
+	SET
+		%s
+	OUTPUT %s
+	WHERE %s`
+
+	templUpdateNoReturn = `UPDATE %s
+	SET
+		%s
+	WHERE %s`
+
+	templUpsert = `INSERT INTO %s (
+		%s
+	) VALUES (
+		%s
+	)
+	ON CONFLICT %s
+	RETURNING %s`
+
+	templUpsertNoReturn = `INSERT INTO %s (
+		%s
+	) VALUES (
+		%s
+	)
+	ON CONFLICT %s`
+
+	templConflictUpdate = `%sDO UPDATE SET
+		%s`
+
+	templUpsertDuplicateKey = `INSERT INTO %s (
+		%s
+	) VALUES (
+		%s
+	)
+	ON DUPLICATE KEY UPDATE
+		%s`
+
+	templUpsertIgnore = `INSERT IGNORE INTO %s (
+		%s
+	) VALUES (
+		%s
+	)`
+
+	templUpsertMerge = `MERGE INTO %s WITH (HOLDLOCK) AS target
+	USING (VALUES (%s)) AS source (%s)
+	ON %s%s
+	WHEN NOT MATCHED THEN INSERT (
+		%s
+	) VALUES (
+		%s
+	)%s;`
+
 	templDelete = `DELETE FROM %s WHERE %s`
+
+	templCreateTable = `CREATE TABLE %s%s (
+		%s
+	)`
+	templCreateTableIfNotExistsSQLServer = `IF OBJECT_ID(N'%s', N'U') IS NULL
+	CREATE TABLE %s (
+		%s
+	)`
+	templDropTable = `DROP TABLE %s%s`
 )
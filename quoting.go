@@ -0,0 +1,87 @@
+package dbgen
+
+import (
+	"strings"
+)
+
+// QuoteMode when identifiers are quoted
+type QuoteMode int
+
+const (
+	// QuoteReserved quote identifiers that are reserved words or would not
+	// keep their case unquoted, the default
+	QuoteReserved QuoteMode = iota
+	// QuoteAlways quote every identifier
+	QuoteAlways
+	// QuoteNever never quote identifiers
+	QuoteNever
+)
+
+// Quoter quotes identifiers for a dialect
+type Quoter struct {
+	Dialect Dialect
+	Mode    QuoteMode
+}
+
+// Ident quote an identifier if the quote mode requires it. Identifiers that
+// are already quoted, qualified or look like expressions are left as is.
+func (qt Quoter) Ident(ident string) string {
+	if qt.Mode == QuoteNever || ident == "" || isExpression(ident) {
+		return ident
+	}
+
+	if qt.Mode == QuoteAlways || needsQuoting(ident) {
+		return dialectOrDefault(qt.Dialect).QuoteIdent(ident)
+	}
+
+	return ident
+}
+
+// needsQuoting whether an identifier is a reserved word or is not a plain
+// lower case identifier
+func needsQuoting(ident string) bool {
+	if reservedWords[strings.ToLower(ident)] {
+		return true
+	}
+
+	for i := 0; i < len(ident); i++ {
+		c := ident[i]
+		if c >= 'a' && c <= 'z' || c == '_' || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return true
+	}
+
+	return false
+}
+
+// isExpression whether an identifier is already quoted, qualified or an
+// expression rather than a bare column or table name
+func isExpression(ident string) bool {
+	return strings.ContainsAny(ident, "\"`[]().* ")
+}
+
+// reservedWords words reserved by at least one of the supported dialects
+var reservedWords = map[string]bool{
+	"all": true, "alter": true, "analyse": true, "analyze": true,
+	"and": true, "any": true, "array": true, "as": true, "asc": true,
+	"between": true, "both": true, "by": true, "case": true, "cast": true,
+	"check": true, "collate": true, "column": true, "constraint": true,
+	"create": true, "cross": true, "current_date": true,
+	"current_time": true, "current_timestamp": true, "current_user": true,
+	"database": true, "default": true, "delete": true, "desc": true,
+	"distinct": true, "do": true, "drop": true, "else": true, "end": true,
+	"except": true, "exists": true, "false": true, "fetch": true,
+	"for": true, "foreign": true, "from": true, "full": true, "grant": true,
+	"group": true, "having": true, "in": true, "index": true, "inner": true,
+	"insert": true, "intersect": true, "into": true, "is": true,
+	"join": true, "key": true, "leading": true, "left": true, "like": true,
+	"limit": true, "natural": true, "not": true, "null": true,
+	"offset": true, "on": true, "only": true, "or": true, "order": true,
+	"outer": true, "primary": true, "references": true, "returning": true,
+	"right": true, "select": true, "session_user": true, "set": true,
+	"some": true, "table": true, "then": true, "to": true,
+	"trailing": true, "true": true, "union": true, "unique": true,
+	"update": true, "user": true, "using": true, "values": true,
+	"when": true, "where": true, "window": true, "with": true,
+}
@@ -0,0 +1,211 @@
+package dbgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Cardinality the rows a registered query returns, named after the sqlc
+// query annotations
+type Cardinality string
+
+// Cardinalities of registered queries
+const (
+	CardinalityOne      Cardinality = ":one"
+	CardinalityMany     Cardinality = ":many"
+	CardinalityExec     Cardinality = ":exec"
+	CardinalityExecRows Cardinality = ":execrows"
+)
+
+// RegisteredQuery a query registered in a registry under a name
+type RegisteredQuery struct {
+	Name        string      `json:"name"`
+	Table       string      `json:"table"`
+	Operation   Operation   `json:"operation"`
+	Cardinality Cardinality `json:"cardinality"`
+	SQL         string      `json:"sql"`
+}
+
+// Registry a catalog of the queries of an application registered under
+// names, exportable as SQL, JSON or Markdown for review
+type Registry struct {
+	mu      sync.Mutex
+	queries map[string]RegisteredQuery
+}
+
+// NewRegistry construct a new empty registry
+func NewRegistry() *Registry {
+	return &Registry{queries: map[string]RegisteredQuery{}}
+}
+
+// RegisterGet register a get query selecting many rows, returning the query
+// named for middleware
+func (r *Registry) RegisterGet(name string, q GetQuery) (GetQuery, error) {
+	if err := r.register(name, q.query, q.Err(), OpSelect, CardinalityMany, q.String()); err != nil {
+		return q, err
+	}
+	return q.Named(name), nil
+}
+
+// RegisterGetOne register a get query selecting a single row, returning the
+// query named for middleware
+func (r *Registry) RegisterGetOne(name string, q GetQuery) (GetQuery, error) {
+	if err := r.register(name, q.query, q.Err(), OpSelectOne, CardinalityOne, q.String()); err != nil {
+		return q, err
+	}
+	return q.Named(name), nil
+}
+
+// RegisterInsert register an insert query, returning the query named for
+// middleware
+func (r *Registry) RegisterInsert(name string, q InsertQuery) (InsertQuery, error) {
+	if err := r.register(name, q.query, q.Err(), OpInsert, writeCardinality(q.query), q.String()); err != nil {
+		return q, err
+	}
+	return q.Named(name), nil
+}
+
+// RegisterUpdate register an update query, returning the query named for
+// middleware
+func (r *Registry) RegisterUpdate(name string, q UpdateQuery) (UpdateQuery, error) {
+	if err := r.register(name, q.query, q.Err(), OpUpdate, writeCardinality(q.query), q.String()); err != nil {
+		return q, err
+	}
+	return q.Named(name), nil
+}
+
+// RegisterUpsert register an upsert query, returning the query named for
+// middleware
+func (r *Registry) RegisterUpsert(name string, q UpsertQuery) (UpsertQuery, error) {
+	if err := r.register(name, q.query, q.Err(), OpUpsert, writeCardinality(q.query), q.String()); err != nil {
+		return q, err
+	}
+	return q.Named(name), nil
+}
+
+// RegisterDelete register a delete query, returning the query named for
+// middleware
+func (r *Registry) RegisterDelete(name string, q DeleteQuery) (DeleteQuery, error) {
+	if err := r.register(name, q.query, q.Err(), OpDelete, CardinalityExecRows, q.String()); err != nil {
+		return q, err
+	}
+	return q.Named(name), nil
+}
+
+// RegisterCount register a count query, returning the query named for
+// middleware
+func (r *Registry) RegisterCount(name string, q CountQuery) (CountQuery, error) {
+	if err := r.register(name, q.query, q.Err(), OpCount, CardinalityOne, q.String()); err != nil {
+		return q, err
+	}
+	return q.Named(name), nil
+}
+
+// RegisterExists register an exists query, returning the query named for
+// middleware
+func (r *Registry) RegisterExists(name string, q ExistsQuery) (ExistsQuery, error) {
+	if err := r.register(name, q.query, q.Err(), OpExists, CardinalityOne, q.String()); err != nil {
+		return q, err
+	}
+	return q.Named(name), nil
+}
+
+// Must get the query registered by a Register method, panicking if it
+// failed, e.g. to register queries in package level variables
+func Must[Q any](q Q, err error) Q {
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Queries get the registered queries sorted by name
+func (r *Registry) Queries() []RegisteredQuery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	queries := make([]RegisteredQuery, 0, len(r.queries))
+	for _, q := range r.queries {
+		queries = append(queries, q)
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].Name < queries[j].Name
+	})
+
+	return queries
+}
+
+// WriteSQL write the catalog as a .sql file, each query preceded by a
+// "-- name: GetUser :one" comment
+func (r *Registry) WriteSQL(w io.Writer) error {
+	var b strings.Builder
+	for i, q := range r.Queries() {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "-- name: %s %s\n%s;\n", q.Name, q.Cardinality, q.SQL)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON write the catalog as a JSON array
+func (r *Registry) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Queries())
+}
+
+// WriteMarkdown write the catalog as a Markdown document
+func (r *Registry) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Queries\n")
+	for _, q := range r.Queries() {
+		fmt.Fprintf(
+			&b,
+			"\n## %s\n\nTable `%s`, %s `%s`\n\n```sql\n%s\n```\n",
+			q.Name, q.Table, q.Operation, q.Cardinality, q.SQL,
+		)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// register add a query to the registry, failing if the name is taken or the
+// query failed to build
+func (r *Registry) register(name string, q query, err error, op Operation, c Cardinality, qs string) error {
+	if err != nil {
+		return fmt.Errorf("dbgen: query %s: %w", name, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.queries[name]; ok {
+		return fmt.Errorf("dbgen: query %s registered twice", name)
+	}
+
+	r.queries[name] = RegisteredQuery{
+		Name:        name,
+		Table:       q.tableName,
+		Operation:   op,
+		Cardinality: c,
+		SQL:         qs,
+	}
+	return nil
+}
+
+// writeCardinality the cardinality of a write query, returning a row when
+// it has return fields its dialect can return
+func writeCardinality(q query) Cardinality {
+	if returningStyle(q.dialect, q.returnFields) == ReturningNone {
+		return CardinalityExec
+	}
+	return CardinalityOne
+}
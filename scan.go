@@ -0,0 +1,145 @@
+package dbgen
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// Rows the rows of a query result, satisfied by *sql.Rows and pgx.Rows
+type Rows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}
+
+// ScanTargets get pointers to the fields of the struct pointed to by dest for
+// each column, matched by db tag, suitable for scanning a row. Columns without
+// a field are scanned into a discarded value and nil embedded struct pointers
+// are allocated. A dest that is not a struct is scanned as a single column.
+func ScanTargets(dest interface{}, columns []string) ([]interface{}, error) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, fmt.Errorf("dbgen: scan destination must be a non nil pointer, got %T", dest)
+	}
+
+	elem := v.Elem()
+	if elem.Kind() != reflect.Struct || isScannable(v.Type()) {
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("dbgen: cannot scan %d columns into %T", len(columns), dest)
+		}
+		return []interface{}{dest}, nil
+	}
+
+	fields, err := getFieldsByTag("db", dest)
+	if err != nil {
+		return nil, err
+	}
+
+	byColumn := make(map[string][]int, len(fields))
+	for _, f := range fields {
+		byColumn[f.column] = f.index
+	}
+
+	targets := make([]interface{}, len(columns))
+	for i, column := range columns {
+		index, ok := byColumn[column]
+		if !ok {
+			targets[i] = new(interface{})
+			continue
+		}
+		targets[i] = allocFieldByIndex(elem, index).Addr().Interface()
+	}
+
+	return targets, nil
+}
+
+// ScanAll scan every row into dest, a pointer to a slice of structs, struct
+// pointers or single column values, returning the number of rows scanned
+func ScanAll(rows Rows, columns []string, dest interface{}) (int, error) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return 0, fmt.Errorf("dbgen: scan destination must be a pointer to a slice, got %T", dest)
+	}
+
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	n := 0
+	for rows.Next() {
+		row := reflect.New(elemType)
+		targets, err := ScanTargets(row.Interface(), columns)
+		if err != nil {
+			return n, err
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return n, err
+		}
+
+		if isPtr {
+			slice = reflect.Append(slice, row)
+		} else {
+			slice = reflect.Append(slice, row.Elem())
+		}
+		n++
+	}
+	v.Elem().Set(slice)
+
+	return n, rows.Err()
+}
+
+// ScanOne scan the first row into dest, returning false if there were no rows
+func ScanOne(rows Rows, columns []string, dest interface{}) (bool, error) {
+	if !rows.Next() {
+		return false, rows.Err()
+	}
+
+	targets, err := ScanTargets(dest, columns)
+	if err != nil {
+		return true, err
+	}
+
+	return true, rows.Scan(targets...)
+}
+
+// allocFieldByIndex get the nested field of a struct value, allocating nil
+// struct pointers on the way
+func allocFieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 {
+			for v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// isScannable whether values of the pointer type scan themselves, such as
+// *time.Time or *sql.NullString, rather than being scanned field by field
+func isScannable(t reflect.Type) bool {
+	if t.Implements(scannerType) {
+		return true
+	}
+	return t.Elem().PkgPath() == "time" && t.Elem().Name() == "Time"
+}
+
+var scannerType = reflect.TypeOf((*interface {
+	Scan(src interface{}) error
+})(nil)).Elem()
+
+// isValuer whether values of the type are converted to driver values by
+// themselves, such as sql.NullString
+func isValuer(t reflect.Type) bool {
+	return t.Implements(valuerType) || reflect.PtrTo(t).Implements(valuerType)
+}
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
@@ -0,0 +1,292 @@
+// Package sqladapter adapts database/sql connections to the dbgen querier
+// interfaces, binding the named parameters of dbgen queries from struct db
+// tags and scanning rows back into structs.
+package sqladapter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sync"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+)
+
+// Conn the query methods shared by *sql.DB, *sql.Tx and *sql.Conn
+type Conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Adapter a database/sql connection satisfying the dbgen querier interfaces
+type Adapter struct {
+	conn     Conn
+	dialect  dbgen.Dialect
+	compiled *sync.Map
+}
+
+var _ dbgen.TxBeginner = (*Adapter)(nil)
+
+// New adapt a *sql.DB, *sql.Tx or *sql.Conn, compiling queries to the
+// positional parameters of the dialect of its driver
+func New(conn Conn, dialect dbgen.Dialect) *Adapter {
+	return &Adapter{
+		conn:     conn,
+		dialect:  dialect,
+		compiled: &sync.Map{},
+	}
+}
+
+// BeginTx begin a transaction on a *sql.DB or *sql.Conn, satisfying
+// dbgen.TxBeginner
+func (a *Adapter) BeginTx(ctx context.Context, opts dbgen.TxOptions) (dbgen.TxCommitter, error) {
+	beginner, ok := a.conn.(interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return nil, errors.New("sqladapter: connection cannot begin a transaction")
+	}
+
+	tx, err := beginner.BeginTx(ctx, &sql.TxOptions{
+		Isolation: opts.Isolation,
+		ReadOnly:  opts.ReadOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &txAdapter{
+		Adapter: &Adapter{conn: tx, dialect: a.dialect, compiled: a.compiled},
+		tx:      tx,
+	}, nil
+}
+
+// txAdapter a *sql.Tx satisfying dbgen.TxCommitter
+type txAdapter struct {
+	*Adapter
+	tx *sql.Tx
+}
+
+func (t *txAdapter) Commit(ctx context.Context) error   { return t.tx.Commit() }
+func (t *txAdapter) Rollback(ctx context.Context) error { return t.tx.Rollback() }
+
+// Select select rows into dest, a pointer to a slice
+func (a *Adapter) Select(query string, dest interface{}, args ...interface{}) error {
+	return a.SelectContext(context.Background(), query, dest, args...)
+}
+
+// SelectContext select rows into dest, a pointer to a slice
+func (a *Adapter) SelectContext(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
+	c := a.compile(query)
+	bound, err := c.BindArgs(args...)
+	if err != nil {
+		return err
+	}
+
+	return a.QueryPositionalContext(ctx, c.SQL, dest, bound...)
+}
+
+// SelectOne select a single row into dest, sql.ErrNoRows if there is none
+func (a *Adapter) SelectOne(query string, dest interface{}, args ...interface{}) error {
+	return a.SelectOneContext(context.Background(), query, dest, args...)
+}
+
+// SelectOneContext select a single row into dest, sql.ErrNoRows if there is none
+func (a *Adapter) SelectOneContext(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
+	c := a.compile(query)
+	bound, err := c.BindArgs(args...)
+	if err != nil {
+		return err
+	}
+
+	return a.queryOne(ctx, c.SQL, dest, bound...)
+}
+
+// SelectScalar select a single column of a single row into dest, such as
+// the result of a count or exists query
+func (a *Adapter) SelectScalar(query string, dest interface{}, args ...interface{}) error {
+	return a.SelectOneContext(context.Background(), query, dest, args...)
+}
+
+// SelectScalarContext select a single column of a single row into dest,
+// such as the result of a count or exists query
+func (a *Adapter) SelectScalarContext(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
+	return a.SelectOneContext(ctx, query, dest, args...)
+}
+
+// Insert insert val, scanning returned columns back into val
+func (a *Adapter) Insert(query string, val interface{}) error {
+	return a.InsertContext(context.Background(), query, val)
+}
+
+// InsertContext insert val, scanning returned columns back into val
+func (a *Adapter) InsertContext(ctx context.Context, query string, val interface{}) error {
+	return a.write(ctx, query, val)
+}
+
+// InsertMany insert rows bound from the indexed arguments of a bulk insert
+func (a *Adapter) InsertMany(query string, args map[string]interface{}) error {
+	return a.InsertManyContext(context.Background(), query, args)
+}
+
+// InsertManyContext insert rows bound from the indexed arguments of a bulk insert
+func (a *Adapter) InsertManyContext(ctx context.Context, query string, args map[string]interface{}) error {
+	c := a.compile(query)
+	bound, err := c.Bind(args)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.conn.ExecContext(ctx, c.SQL, bound...)
+	return err
+}
+
+// Update update the row of val, scanning returned columns back into val
+func (a *Adapter) Update(query string, val interface{}) error {
+	return a.UpdateContext(context.Background(), query, val)
+}
+
+// UpdateContext update the row of val, scanning returned columns back into val
+func (a *Adapter) UpdateContext(ctx context.Context, query string, val interface{}) error {
+	return a.write(ctx, query, val)
+}
+
+// Upsert upsert val, scanning returned columns back into val
+func (a *Adapter) Upsert(query string, val interface{}) error {
+	return a.UpsertContext(context.Background(), query, val)
+}
+
+// UpsertContext upsert val, scanning returned columns back into val
+func (a *Adapter) UpsertContext(ctx context.Context, query string, val interface{}) error {
+	return a.write(ctx, query, val)
+}
+
+// Delete delete rows, returning the number of rows affected
+func (a *Adapter) Delete(query string, args ...interface{}) (int64, error) {
+	return a.DeleteContext(context.Background(), query, args...)
+}
+
+// DeleteContext delete rows, returning the number of rows affected
+func (a *Adapter) DeleteContext(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	c := a.compile(query)
+	bound, err := c.BindArgs(args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return a.ExecPositionalContext(ctx, c.SQL, bound...)
+}
+
+// QueryPositional run a compiled query, scanning rows into dest
+func (a *Adapter) QueryPositional(query string, dest interface{}, args ...interface{}) error {
+	return a.QueryPositionalContext(context.Background(), query, dest, args...)
+}
+
+// QueryPositionalContext run a compiled query, scanning rows into dest, a
+// pointer to a slice for every row or any other pointer for a single row
+func (a *Adapter) QueryPositionalContext(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
+	if !isSlicePtr(dest) {
+		return a.queryOne(ctx, query, dest, args...)
+	}
+
+	rows, err := a.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	_, err = dbgen.ScanAll(rows, columns, dest)
+	return err
+}
+
+// ExecPositional run a compiled query, returning the number of rows affected
+func (a *Adapter) ExecPositional(query string, args ...interface{}) (int64, error) {
+	return a.ExecPositionalContext(context.Background(), query, args...)
+}
+
+// ExecPositionalContext run a compiled query, returning the number of rows affected
+func (a *Adapter) ExecPositionalContext(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	res, err := a.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// write run an insert or update bound from val, scanning the first returned
+// row, if any, back into val
+func (a *Adapter) write(ctx context.Context, query string, val interface{}) error {
+	c := a.compile(query)
+	bound, err := c.Bind(val)
+	if err != nil {
+		return err
+	}
+
+	rows, err := a.conn.QueryContext(ctx, c.SQL, bound...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if reflect.ValueOf(val).Kind() == reflect.Ptr {
+		columns, err := rows.Columns()
+		if err != nil {
+			return err
+		}
+
+		if _, err := dbgen.ScanOne(rows, columns, val); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (a *Adapter) queryOne(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
+	rows, err := a.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	found, err := dbgen.ScanOne(rows, columns, dest)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// compile compile a named query to the dialect of the adapter, caching it
+func (a *Adapter) compile(query string) dbgen.CompiledQuery {
+	if c, ok := a.compiled.Load(query); ok {
+		return c.(dbgen.CompiledQuery)
+	}
+
+	c := dbgen.Compile(query, a.dialect)
+	a.compiled.Store(query, c)
+	return c
+}
+
+func isSlicePtr(dest interface{}) bool {
+	t := reflect.TypeOf(dest)
+	return t != nil &&
+		t.Kind() == reflect.Ptr &&
+		t.Elem().Kind() == reflect.Slice &&
+		t.Elem().Elem().Kind() != reflect.Uint8
+}
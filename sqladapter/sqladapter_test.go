@@ -0,0 +1,272 @@
+package sqladapter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type user struct {
+	ID        string `db:"id,pk"`
+	Name      string `db:"name"`
+	CreatedAt string `db:"created_at,readonly"`
+}
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE users (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		created_at TEXT NOT NULL DEFAULT 'now'
+	)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func Test_Adapter(t *testing.T) {
+
+	db := openDB(t)
+	ctx := context.Background()
+	a := New(db, dbgen.SQLite)
+
+	opts := struct {
+		get    dbgen.GetQueryOptions
+		insert dbgen.InsertQueryOptions
+		update dbgen.UpdateQueryOptions
+		delete dbgen.DeleteQueryOptions
+	}{
+		dbgen.GetQueryOptions{Dialect: dbgen.SQLite},
+		dbgen.InsertQueryOptions{Dialect: dbgen.SQLite},
+		dbgen.UpdateQueryOptions{Dialect: dbgen.SQLite},
+		dbgen.DeleteQueryOptions{Dialect: dbgen.SQLite},
+	}
+
+	insert := dbgen.NewInsert("users", user{}, opts.insert).FnContext()
+	insertMany := dbgen.NewInsert("users", user{}, opts.insert).FnManyContext()
+	get := dbgen.NewGet("users", user{}, opts.get).FnSelectOneContext()
+	list := dbgen.NewGet("users", user{}, opts.get).Where("name<>:name").FnSelectContext()
+	update := dbgen.NewUpdate("users", user{}, opts.update).FnContext()
+	del := dbgen.NewDelete("users", user{}, opts.delete).FnContext()
+
+	u := user{ID: "1", Name: "ada"}
+	if err := insert(ctx, a, &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.CreatedAt != "now" {
+		t.Errorf("Insert did not scan returned columns, got %+v", u)
+	}
+
+	if err := insertMany(ctx, a, []user{{ID: "2", Name: "bob"}, {ID: "3", Name: "cy"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got user
+	if err := get(ctx, a, &got, "2"); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "bob" {
+		t.Errorf("SelectOne = %+v", got)
+	}
+
+	u.Name = "ada lovelace"
+	if err := update(ctx, a, &u); err != nil {
+		t.Fatal(err)
+	}
+
+	var users []user
+	if err := list(ctx, a, &users, map[string]interface{}{"name": "cy"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].Name != "ada lovelace" || users[1].Name != "bob" {
+		t.Errorf("Select = %+v", users)
+	}
+
+	n, err := del(ctx, a, "3")
+	if err != nil || n != 1 {
+		t.Errorf("Delete = %d, %v", n, err)
+	}
+
+	if err := get(ctx, a, &got, "3"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("SelectOne error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func Test_Adapter_Tx(t *testing.T) {
+
+	db := openDB(t)
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	insert := dbgen.NewInsert("users", user{}, dbgen.InsertQueryOptions{Dialect: dbgen.SQLite}).Fn()
+	if err := insert(New(tx, dbgen.SQLite), &user{ID: "1", Name: "ada"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	compiled := dbgen.Compile("SELECT COUNT(*) FROM users", dbgen.SQLite)
+	if err := compiled.FnQuery()(New(db, dbgen.SQLite), &count, nil); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("rolled back insert was kept, count = %d", count)
+	}
+}
+
+func Test_WithTx(t *testing.T) {
+
+	db := openDB(t)
+	ctx := context.Background()
+	a := New(db, dbgen.SQLite)
+
+	insert := dbgen.NewInsert("users", user{}, dbgen.InsertQueryOptions{Dialect: dbgen.SQLite}).FnContext()
+	wantErr := errors.New("abort")
+
+	err := dbgen.WithTx(ctx, a, func(tx dbgen.Tx) error {
+		if err := insert(ctx, tx, &user{ID: "1", Name: "ada"}); err != nil {
+			return err
+		}
+		return wantErr
+	})
+	if err != wantErr {
+		t.Errorf("WithTx error = %v, want %v", err, wantErr)
+	}
+
+	err = dbgen.WithTx(ctx, a, func(tx dbgen.Tx) error {
+		return insert(ctx, tx, &user{ID: "2", Name: "bob"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	compiled := dbgen.Compile("SELECT id FROM users", dbgen.SQLite)
+	if err := compiled.FnQuery()(a, &ids, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != "2" {
+		t.Errorf("committed ids = %v, want [2]", ids)
+	}
+}
+
+func Test_InExpansion(t *testing.T) {
+
+	db := openDB(t)
+	ctx := context.Background()
+	a := New(db, dbgen.SQLite)
+
+	insertMany := dbgen.NewInsert("users", user{}, dbgen.InsertQueryOptions{Dialect: dbgen.SQLite}).FnManyContext()
+	err := insertMany(ctx, a, []user{{ID: "1", Name: "ada"}, {ID: "2", Name: "bob"}, {ID: "3", Name: "cy"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := dbgen.NewGetT[user]("users", dbgen.GetQueryOptions{Dialect: dbgen.SQLite}).
+		Where(dbgen.In("id", "ids")).
+		FnSelect()
+
+	users, err := list(ctx, a, []string{"1", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].Name != "ada" || users[1].Name != "cy" {
+		t.Errorf("Select IN = %+v", users)
+	}
+
+	users, err = list(ctx, a, []string{})
+	if err != nil || len(users) != 0 {
+		t.Errorf("Select IN empty = %+v, %v", users, err)
+	}
+}
+
+func Test_Keyset(t *testing.T) {
+
+	db := openDB(t)
+	ctx := context.Background()
+	a := New(db, dbgen.SQLite)
+
+	insertMany := dbgen.NewInsert("users", user{}, dbgen.InsertQueryOptions{Dialect: dbgen.SQLite}).FnManyContext()
+	err := insertMany(ctx, a, []user{{ID: "1", Name: "ada"}, {ID: "2", Name: "bob"}, {ID: "3", Name: "cy"}, {ID: "4", Name: "dee"}, {ID: "5", Name: "eve"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := dbgen.NewGetT[user]("users", dbgen.GetQueryOptions{Dialect: dbgen.SQLite}).
+		Where(dbgen.Ne("name", "skip")).
+		Keyset(dbgen.Desc, "created_at", "id").
+		Limit(2).
+		FnPage()
+
+	var ids []string
+	var cursor string
+	for pages := 0; pages < 5; pages++ {
+		p, err := page(ctx, a, cursor, "bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, u := range p.Rows {
+			ids = append(ids, u.ID)
+		}
+		if cursor = p.Next; cursor == "" {
+			break
+		}
+	}
+
+	if got := strings.Join(ids, ","); got != "5,4,3,1" {
+		t.Errorf("FnPage() ids = %s, want 5,4,3,1", got)
+	}
+
+	if _, err := page(ctx, a, "bm90IGEgY3Vyc29y", "bob"); !errors.Is(err, dbgen.ErrInvalidCursor) {
+		t.Errorf("FnPage() invalid cursor error = %v", err)
+	}
+}
+
+func Test_CountExists(t *testing.T) {
+
+	db := openDB(t)
+	ctx := context.Background()
+	a := New(db, dbgen.SQLite)
+
+	insertMany := dbgen.NewInsert("users", user{}, dbgen.InsertQueryOptions{Dialect: dbgen.SQLite}).FnManyContext()
+	err := insertMany(ctx, a, []user{{ID: "1", Name: "ada"}, {ID: "2", Name: "bob"}, {ID: "3", Name: "bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := dbgen.NewGet("users", user{}, dbgen.GetQueryOptions{Dialect: dbgen.SQLite}).Where(dbgen.Eq("name")).Limit(1)
+
+	n, err := byName.Count().FnContext()(ctx, a, "bob")
+	if err != nil || n != 2 {
+		t.Errorf("Count() = %d, %v, want 2", n, err)
+	}
+
+	exists := byName.Exists().Fn()
+	if ok, err := exists(a, "bob"); err != nil || !ok {
+		t.Errorf("Exists(bob) = %v, %v, want true", ok, err)
+	}
+	if ok, err := exists(a, "cy"); err != nil || ok {
+		t.Errorf("Exists(cy) = %v, %v, want false", ok, err)
+	}
+}
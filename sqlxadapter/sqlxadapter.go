@@ -0,0 +1,48 @@
+// Package sqlxadapter adapts sqlx connections to the dbgen querier interfaces.
+//
+// The adapter wraps sqladapter over the database/sql connection of a
+// *sqlx.DB or *sqlx.Tx, compiling queries to the bind variables of the sqlx
+// driver. Named parameters are bound and rows are scanned by dbgen rather
+// than by sqlx's NamedQuery, Select and Get: sqlx maps nested structs to
+// dotted columns ("address.street"), so the prefixed columns of nested
+// structs ("address_street") used by every dbgen query would not map.
+package sqlxadapter
+
+import (
+	"strings"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+	"github.com/JonathanFejtek/go-dbgen/sqladapter"
+	"github.com/jmoiron/sqlx"
+)
+
+// Adapter a *sqlx.DB or *sqlx.Tx satisfying the dbgen querier interfaces
+type Adapter struct {
+	*sqladapter.Adapter
+}
+
+var _ dbgen.TxBeginner = (*Adapter)(nil)
+
+// New adapt a *sqlx.DB or *sqlx.Tx, compiling queries to the bind variables
+// of its driver
+func New(ext sqlx.ExtContext) *Adapter {
+	return &Adapter{
+		Adapter: sqladapter.New(ext, dialectOf(ext.DriverName())),
+	}
+}
+
+// dialectOf the dialect whose positional parameters are the bind variables
+// of a sqlx driver
+func dialectOf(driverName string) dbgen.Dialect {
+	switch sqlx.BindType(driverName) {
+	case sqlx.DOLLAR:
+		return dbgen.Postgres
+	case sqlx.AT:
+		return dbgen.SQLServer
+	}
+
+	if strings.Contains(driverName, "sqlite") {
+		return dbgen.SQLite
+	}
+	return dbgen.MySQL
+}
@@ -0,0 +1,174 @@
+package sqlxadapter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	dbgen "github.com/JonathanFejtek/go-dbgen"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type user struct {
+	ID        string `db:"id,pk"`
+	Name      string `db:"name"`
+	CreatedAt string `db:"created_at,readonly"`
+}
+
+func Test_Adapter(t *testing.T) {
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	db.MustExec(`CREATE TABLE users (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		created_at TEXT NOT NULL DEFAULT 'now'
+	)`)
+
+	ctx := context.Background()
+	tx, err := db.Beginx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := New(tx)
+
+	insert := dbgen.NewInsert("users", user{}, dbgen.InsertQueryOptions{Dialect: dbgen.SQLite}).FnContext()
+	insertMany := dbgen.NewInsert("users", user{}, dbgen.InsertQueryOptions{Dialect: dbgen.SQLite}).FnMany()
+	get := dbgen.NewGet("users", user{}).FnSelectOne()
+	list := dbgen.NewGet("users", user{}).Where("name<>:name").FnSelect()
+	update := dbgen.NewUpdate("users", user{}, dbgen.UpdateQueryOptions{Dialect: dbgen.SQLite}).Fn()
+	del := dbgen.NewDelete("users", user{}).Fn()
+
+	u := user{ID: "1", Name: "ada"}
+	if err := insert(ctx, a, &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.CreatedAt != "now" {
+		t.Errorf("Insert did not scan returned columns, got %+v", u)
+	}
+
+	if err := insertMany(a, []user{{ID: "2", Name: "bob"}, {ID: "3", Name: "cy"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got user
+	if err := get(a, &got, user{ID: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "bob" {
+		t.Errorf("SelectOne = %+v", got)
+	}
+
+	u.Name = "ada lovelace"
+	if err := update(a, &u); err != nil {
+		t.Fatal(err)
+	}
+
+	var users []user
+	if err := list(a, &users, "cy"); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].Name != "ada lovelace" || users[1].Name != "bob" {
+		t.Errorf("Select = %+v", users)
+	}
+
+	n, err := del(a, "3")
+	if err != nil || n != 1 {
+		t.Errorf("Delete = %d, %v", n, err)
+	}
+
+	if err := get(a, &got, "3"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("SelectOne error = %v, want sql.ErrNoRows", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type address struct {
+	Street string `db:"street"`
+	City   string `db:"city"`
+}
+
+type customer struct {
+	ID      string  `db:"id,pk"`
+	Address address `db:"address_"`
+}
+
+func Test_NestedStructs(t *testing.T) {
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	db.MustExec(`CREATE TABLE customers (
+		id TEXT PRIMARY KEY,
+		address_street TEXT NOT NULL,
+		address_city TEXT NOT NULL
+	)`)
+
+	ctx := context.Background()
+	a := New(db)
+
+	insert := dbgen.NewInsert("customers", customer{}, dbgen.InsertQueryOptions{Dialect: dbgen.SQLite}).FnContext()
+	update := dbgen.NewUpdate("customers", customer{}, dbgen.UpdateQueryOptions{Dialect: dbgen.SQLite}).FnContext()
+	get := dbgen.NewGet("customers", customer{}).FnSelectOneContext()
+	list := dbgen.NewGet("customers", customer{}).All().FnSelectContext()
+
+	c := customer{ID: "1", Address: address{Street: "Rue de Rivoli", City: "Paris"}}
+	if err := insert(ctx, a, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Address.City = "Lyon"
+	if err := update(ctx, a, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	var got customer
+	if err := get(ctx, a, &got, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if got != c {
+		t.Errorf("SelectOne = %+v, want %+v", got, c)
+	}
+
+	var customers []customer
+	if err := list(ctx, a, &customers); err != nil {
+		t.Fatal(err)
+	}
+	if len(customers) != 1 || customers[0] != c {
+		t.Errorf("Select = %+v, want [%+v]", customers, c)
+	}
+}
+
+func Test_DialectOf(t *testing.T) {
+
+	tests := []struct {
+		driverName string
+		want       dbgen.Dialect
+	}{
+		{"postgres", dbgen.Postgres},
+		{"pgx", dbgen.Postgres},
+		{"sqlserver", dbgen.SQLServer},
+		{"mysql", dbgen.MySQL},
+		{"sqlite3", dbgen.SQLite},
+	}
+
+	for _, tt := range tests {
+		if got := dialectOf(tt.driverName); got.Name() != tt.want.Name() {
+			t.Errorf("dialectOf(%s) string = %+v ||  \n want %+v", tt.driverName, got.Name(), tt.want.Name())
+		}
+	}
+}
@@ -0,0 +1,28 @@
+SELECT users.id, users.name FROM users WHERE id=:id
+
+INSERT INTO users (
+		id, name
+	) VALUES (
+		:id, :name
+	)
+
+INSERT INTO users (
+		id, name
+	) VALUES
+		(:id_0, :name_0),
+		(:id_1, :name_1)
+
+UPDATE users
+	SET
+		name=:name
+	WHERE id=:id
+
+DELETE FROM users WHERE id=:id
+
+INSERT INTO users (
+		id, name
+	) VALUES (
+		:id, :name
+	)
+	ON DUPLICATE KEY UPDATE
+		name=VALUES(name)
@@ -0,0 +1,31 @@
+SELECT users.id, users.name FROM users WHERE id=:id
+
+INSERT INTO users (
+		id, name
+	) VALUES (
+		:id, :name
+	)
+	RETURNING users.id, users.name
+
+INSERT INTO users (
+		id, name
+	) VALUES
+		(:id_0, :name_0),
+		(:id_1, :name_1)
+
+UPDATE users
+	SET
+		name=:name
+	WHERE id=:id
+	RETURNING users.id, users.name
+
+DELETE FROM users WHERE id=:id
+
+INSERT INTO users (
+		id, name
+	) VALUES (
+		:id, :name
+	)
+	ON CONFLICT (id) DO UPDATE SET
+		name=EXCLUDED.name
+	RETURNING users.id, users.name
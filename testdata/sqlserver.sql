@@ -0,0 +1,35 @@
+SELECT users.id, users.name FROM users WHERE id=:id
+
+INSERT INTO users (
+		id, name
+	)
+	OUTPUT INSERTED.id, INSERTED.name
+	VALUES (
+		:id, :name
+	)
+
+INSERT INTO users (
+		id, name
+	) VALUES
+		(:id_0, :name_0),
+		(:id_1, :name_1)
+
+UPDATE users
+	SET
+		name=:name
+	OUTPUT INSERTED.id, INSERTED.name
+	WHERE id=:id
+
+DELETE FROM users WHERE id=:id
+
+MERGE INTO users WITH (HOLDLOCK) AS target
+	USING (VALUES (:id, :name)) AS source (id, name)
+	ON target.id=source.id
+	WHEN MATCHED THEN UPDATE SET
+		name=source.name
+	WHEN NOT MATCHED THEN INSERT (
+		id, name
+	) VALUES (
+		source.id, source.name
+	)
+	OUTPUT INSERTED.id, INSERTED.name;
@@ -0,0 +1,172 @@
+package dbgen
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// DefaultTxAttempts the number of times a transaction is attempted when it
+// fails with a retryable error and no attempt count is given
+const DefaultTxAttempts = 4
+
+// Tx a transaction satisfying every querier interface, passed to the
+// function run by WithTx
+type Tx interface {
+	SelectQuerier
+	SelectOneQuerier
+	SelectContextQuerier
+	SelectOneContextQuerier
+	InsertQuerier
+	InsertContextQuerier
+	BulkInsertQuerier
+	BulkInsertContextQuerier
+	UpdateQuerier
+	UpdateContextQuerier
+	UpsertQuerier
+	UpsertContextQuerier
+	DeleteQuerier
+	DeleteContextQuerier
+	ScalarQuerier
+	ScalarContextQuerier
+	PositionalQuerier
+	PositionalContextQuerier
+	PositionalExecer
+	PositionalContextExecer
+}
+
+// TxCommitter a transaction that can be committed or rolled back
+type TxCommitter interface {
+	Tx
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// TxBeginner interface required to run functions in a transaction
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts TxOptions) (TxCommitter, error)
+}
+
+// TxOptions optional arguments to run a function in a transaction
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+	// MaxAttempts the number of times the transaction is attempted, zero uses
+	// DefaultTxAttempts and one disables retries
+	MaxAttempts int
+	// Backoff the delay before retrying after the nth failed attempt,
+	// exponential with jitter from 10ms by default
+	Backoff func(attempt int) time.Duration
+	// Retryable whether an error is retried, IsRetryable by default, which
+	// recognises Postgres, MySQL and SQL Server driver errors
+	Retryable func(err error) bool
+}
+
+// WithTx run fn in a transaction, committing if fn returns nil and rolling
+// back if it returns an error or panics. Transactions failing with a
+// retryable error, such as a serialization failure or deadlock, are retried
+// with backoff.
+func WithTx(ctx context.Context, db TxBeginner, fn func(tx Tx) error, opts ...TxOptions) error {
+	var options TxOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DefaultTxAttempts
+	}
+	if options.Backoff == nil {
+		options.Backoff = defaultBackoff
+	}
+	if options.Retryable == nil {
+		options.Retryable = IsRetryable
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := runTx(ctx, db, fn, options)
+		if err == nil || attempt >= options.MaxAttempts || !options.Retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(options.Backoff(attempt)):
+		}
+	}
+}
+
+// runTx run fn in a single transaction
+func runTx(ctx context.Context, db TxBeginner, fn func(tx Tx) error, options TxOptions) (err error) {
+	tx, err := db.BeginTx(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// IsRetryable whether an error is a serialization failure, deadlock or lock
+// wait timeout, detected by the SQLSTATE of errors such as *pgconn.PgError,
+// the Number of *mysql.MySQLError (1213, 1205) and the SQLErrorNumber of
+// mssql.Error (1205)
+func IsRetryable(err error) bool {
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		switch stateErr.SQLState() {
+		case "40001", "40P01":
+			return true
+		}
+	}
+
+	var mssqlErr interface{ SQLErrorNumber() int32 }
+	if errors.As(err, &mssqlErr) && mssqlErr.SQLErrorNumber() == 1205 {
+		return true
+	}
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		switch mysqlErrorNumber(e) {
+		case 1213, 1205:
+			return true
+		}
+	}
+	return false
+}
+
+// mysqlErrorNumber the Number of a go-sql-driver *mysql.MySQLError, read by
+// reflection as the driver exposes no method for it, zero for other errors
+func mysqlErrorNumber(err error) uint64 {
+	v := reflect.Indirect(reflect.ValueOf(err))
+	if v.Kind() != reflect.Struct || v.Type().Name() != "MySQLError" {
+		return 0
+	}
+
+	n := v.FieldByName("Number")
+	switch n.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return n.Uint()
+	}
+	return 0
+}
+
+func defaultBackoff(attempt int) time.Duration {
+	base := 10 * time.Millisecond << uint(attempt-1)
+	return base + time.Duration(rand.Int63n(int64(base)))
+}
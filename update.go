@@ -1,6 +1,7 @@
 package dbgen
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -9,12 +10,19 @@ type UpdateQuerier interface {
 	Update(q string, val interface{}) error
 }
 
+// UpdateContextQuerier interface required to build context aware update db functions
+type UpdateContextQuerier interface {
+	UpdateContext(ctx context.Context, q string, val interface{}) error
+}
+
 // MakeUpdateQueryArgs arguments required to make an update query
 type MakeUpdateQueryArgs struct {
 	TableName    string
 	Values       Columns
 	WhereClause  string
 	ReturnFields Columns
+	Dialect      Dialect
+	Quoter       Quoter
 }
 
 // UpdateQuery represents an update query
@@ -37,10 +45,19 @@ func (q UpdateQuery) OmitReturns(fields ...string) UpdateQuery {
 	return nq
 }
 
-// Where set the where clause of the query
-func (q UpdateQuery) Where(whereString string) UpdateQuery {
+// PrimaryKey set the primary key columns matched by the default where
+// clause of the query
+func (q UpdateQuery) PrimaryKey(fields ...string) UpdateQuery {
+	nq := q
+	nq.query = nq.query.setPrimaryKey(fields...)
+	return nq
+}
+
+// Where set the where clause of the update query, a Predicate or a raw SQL
+// string
+func (q UpdateQuery) Where(where interface{}) UpdateQuery {
 	nq := q
-	nq.query = nq.query.where(whereString)
+	nq.query = nq.query.where(where)
 	return nq
 }
 
@@ -50,24 +67,74 @@ func (q UpdateQuery) String() string {
 	return q.makeQuery(
 		MakeUpdateQueryArgs{
 			TableName:    q.tableName,
-			Values:       q.valueFields,
-			WhereClause:  q.whereClause,
+			Values:       q.valueFields.Omit(q.primaryKey.Fields...),
+			WhereClause:  q.whereString(),
 			ReturnFields: q.returnFields,
+			Dialect:      q.dialect,
+			Quoter:       q.quoter,
 		},
 	)
 }
 
+// Err get the error building the update query, returned by its db functions
+func (q UpdateQuery) Err() error {
+	return q.whereErr()
+}
+
+// Compile generate the query with the positional parameters of its dialect
+func (q UpdateQuery) Compile() CompiledQuery {
+	return q.compile(OpUpdate, q.String(), q.Err())
+}
+
+// Named name the update query, passed to middleware
+func (q UpdateQuery) Named(name string) UpdateQuery {
+	nq := q
+	nq.query = nq.query.named(name)
+	return nq
+}
+
+// Use attach middleware to the db functions of the update query
+func (q UpdateQuery) Use(mw ...Middleware) UpdateQuery {
+	nq := q
+	nq.query = nq.query.use(mw...)
+	return nq
+}
+
 // Fn generate the query as a function
 func (q UpdateQuery) Fn() func(tx UpdateQuerier, i interface{}) error {
 	qs := q.String()
+	buildErr := q.Err()
 	return func(tx UpdateQuerier, i interface{}) error {
-		return tx.Update(qs, i)
+		if buildErr != nil {
+			return buildErr
+		}
+		_, err := q.run(context.Background(), OpUpdate, qs, []interface{}{i}, func(ctx context.Context) (Result, error) {
+			return unknownRows(tx.Update(qs, i))
+		})
+		return err
+	}
+}
+
+// FnContext generate the query as a function honouring the context
+func (q UpdateQuery) FnContext() func(ctx context.Context, tx UpdateContextQuerier, i interface{}) error {
+	qs := q.String()
+	buildErr := q.Err()
+	return func(ctx context.Context, tx UpdateContextQuerier, i interface{}) error {
+		if buildErr != nil {
+			return buildErr
+		}
+		_, err := q.run(ctx, OpUpdate, qs, []interface{}{i}, func(ctx context.Context) (Result, error) {
+			return unknownRows(tx.UpdateContext(ctx, qs, i))
+		})
+		return err
 	}
 }
 
 // UpdateQueryOptions optional arguments to create a new update query
 type UpdateQueryOptions struct {
 	MakeQuery func(args MakeUpdateQueryArgs) string
+	Dialect   Dialect
+	Quote     QuoteMode
 }
 
 // NewUpdate construct a new update query
@@ -76,37 +143,40 @@ func NewUpdate(
 	i interface{},
 	opts ...UpdateQueryOptions,
 ) UpdateQuery {
-	tags := getTagsByName("db", i)
+	fields, err := getFieldsByTag("db", i)
 
 	var options UpdateQueryOptions
 	if len(opts) > 0 {
 		options = opts[0]
 	}
 
+	quoter := Quoter{
+		Dialect: dialectOrDefault(options.Dialect),
+		Mode:    options.Quote,
+	}
+	primaryKey, primaryKeyErr := primaryKeyOf(tableName, fields, quoter)
+
 	q := UpdateQuery{
 		query: query{
 			tableName: tableName,
+			columns:   columnsOf(fields, nil),
+			err:       err,
+			dialect:   dialectOrDefault(options.Dialect),
+			quoter:    quoter,
 			valueFields: Columns{
 				TableName: tableName,
-				Fields:    tags,
+				Fields:    columnsOf(fields, field.updatable),
+				Quoter:    quoter,
 			},
 			returnFields: Columns{
 				TableName: tableName,
-				Fields:    tags,
+				Fields:    columnsOf(fields, field.returnable),
+				Quoter:    quoter,
 			},
-			whereClause: DefaultIdentityString,
-		},
-		makeQuery: func(
-			args MakeUpdateQueryArgs,
-		) string {
-			return fmt.Sprintf(
-				templUpdate,
-				args.TableName,
-				args.Values.AsAssignments().Joined(),
-				args.WhereClause,
-				args.ReturnFields.AsSelects().Joined(),
-			)
+			primaryKey:    primaryKey,
+			primaryKeyErr: primaryKeyErr,
 		},
+		makeQuery: makeUpdateQuery,
 	}
 
 	if options.MakeQuery != nil {
@@ -115,3 +185,32 @@ func NewUpdate(
 
 	return q
 }
+
+// makeUpdateQuery render an update query for the dialect of the args
+func makeUpdateQuery(args MakeUpdateQueryArgs) string {
+	switch returningStyle(args.Dialect, args.ReturnFields) {
+	case ReturningNone:
+		return fmt.Sprintf(
+			templUpdateNoReturn,
+			args.Quoter.Ident(args.TableName),
+			args.Values.AsAssignments().Joined(),
+			args.WhereClause,
+		)
+	case ReturningOutput:
+		return fmt.Sprintf(
+			templUpdateOutput,
+			args.Quoter.Ident(args.TableName),
+			args.Values.AsAssignments().Joined(),
+			args.ReturnFields.AsQualified("INSERTED").Joined(),
+			args.WhereClause,
+		)
+	}
+
+	return fmt.Sprintf(
+		templUpdate,
+		args.Quoter.Ident(args.TableName),
+		args.Values.AsAssignments().Joined(),
+		args.WhereClause,
+		args.ReturnFields.AsSelects().Joined(),
+	)
+}
@@ -0,0 +1,366 @@
+package dbgen
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// UpsertQuerier interface required to build an upsert db function
+type UpsertQuerier interface {
+	Upsert(q string, val interface{}) error
+}
+
+// UpsertContextQuerier interface required to build context aware upsert db functions
+type UpsertContextQuerier interface {
+	UpsertContext(ctx context.Context, q string, val interface{}) error
+}
+
+// MakeUpsertQueryArgs arguments required to make an upsert query
+type MakeUpsertQueryArgs struct {
+	TableName      string
+	Values         Columns
+	ConflictFields Columns
+	UpdateFields   Columns
+	DoNothing      bool
+	ReturnFields   Columns
+	Dialect        Dialect
+	Quoter         Quoter
+}
+
+// UpsertQuery represents an insert query that resolves conflicts
+type UpsertQuery struct {
+	query
+	conflictFields Columns
+	updateFields   Columns
+	// updatesSet whether the updated columns were set with DoUpdate rather
+	// than defaulting to the upsertable columns outside the conflict target
+	updatesSet bool
+	doNothing  bool
+	makeQuery  func(args MakeUpsertQueryArgs) string
+}
+
+// OmitValues omit value fields to insert from the query
+func (q UpsertQuery) OmitValues(fields ...string) UpsertQuery {
+	nq := q
+	nq.query = nq.query.omitValues(fields...)
+	return nq
+}
+
+// OmitReturns omit return fields from the query
+func (q UpsertQuery) OmitReturns(fields ...string) UpsertQuery {
+	nq := q
+	nq.query = nq.query.omitReturns(fields...)
+	return nq
+}
+
+// OnConflict set the conflict target columns of the query, the primary key
+// unless set
+func (q UpsertQuery) OnConflict(fields ...string) UpsertQuery {
+	nq := q
+	nq.conflictFields = nq.conflictFields.Set(fields...)
+	return nq
+}
+
+// DoNothing ignore rows that conflict instead of updating them
+func (q UpsertQuery) DoNothing() UpsertQuery {
+	nq := q
+	nq.doNothing = true
+	return nq
+}
+
+// DoUpdate set the columns updated from the excluded row on conflict
+func (q UpsertQuery) DoUpdate(fields ...string) UpsertQuery {
+	nq := q
+	nq.doNothing = false
+	nq.updatesSet = true
+	nq.updateFields = nq.updateFields.Set(fields...)
+	return nq
+}
+
+// OmitUpdates omit columns from being updated on conflict
+func (q UpsertQuery) OmitUpdates(fields ...string) UpsertQuery {
+	nq := q
+	nq.updateFields = nq.updateFields.Omit(fields...)
+	return nq
+}
+
+// String generate the query as a string
+func (q UpsertQuery) String() string {
+	return q.makeQuery(
+		MakeUpsertQueryArgs{
+			TableName:      q.tableName,
+			Values:         q.valueFields,
+			ConflictFields: q.conflictFields,
+			UpdateFields:   q.updates(),
+			DoNothing:      q.doNothing,
+			ReturnFields:   q.returnFields,
+			Dialect:        q.dialect,
+			Quoter:         q.quoter,
+		},
+	)
+}
+
+// Err get the error building the upsert query, returned by its db functions
+func (q UpsertQuery) Err() error {
+	if q.err != nil {
+		return q.err
+	}
+	return q.conflictErr()
+}
+
+// Compile generate the query with the positional parameters of its dialect
+func (q UpsertQuery) Compile() CompiledQuery {
+	return q.compile(OpUpsert, q.String(), q.Err())
+}
+
+// updates the columns updated on conflict, the conflict target columns are
+// not updated unless set with DoUpdate
+func (q UpsertQuery) updates() Columns {
+	if q.updatesSet {
+		return q.updateFields
+	}
+	return q.updateFields.Omit(q.conflictFields.Fields...)
+}
+
+// conflictErr the error of an upsert without conflict target columns its
+// dialect requires: Postgres and SQLite only ignore conflicts without a
+// target, and a SQL Server MERGE matches rows on the target columns
+func (q UpsertQuery) conflictErr() error {
+	if len(q.conflictFields.Fields) > 0 {
+		return nil
+	}
+
+	switch dialectOrDefault(q.dialect).Name() {
+	case "mysql":
+		return nil
+	case "sqlserver":
+		return fmt.Errorf("dbgen: upsert of %s without conflict columns", q.tableName)
+	}
+
+	if q.doNothing || len(q.updates().Fields) == 0 {
+		return nil
+	}
+	return fmt.Errorf("dbgen: upsert of %s updates on conflict without conflict columns", q.tableName)
+}
+
+// Named name the upsert query, passed to middleware
+func (q UpsertQuery) Named(name string) UpsertQuery {
+	nq := q
+	nq.query = nq.query.named(name)
+	return nq
+}
+
+// Use attach middleware to the db functions of the upsert query
+func (q UpsertQuery) Use(mw ...Middleware) UpsertQuery {
+	nq := q
+	nq.query = nq.query.use(mw...)
+	return nq
+}
+
+// Fn generate the query as a db function
+func (q UpsertQuery) Fn() func(tx UpsertQuerier, i interface{}) error {
+	qs := q.String()
+	buildErr := q.Err()
+	return func(tx UpsertQuerier, i interface{}) error {
+		if buildErr != nil {
+			return buildErr
+		}
+		_, err := q.run(context.Background(), OpUpsert, qs, []interface{}{i}, func(ctx context.Context) (Result, error) {
+			return unknownRows(tx.Upsert(qs, i))
+		})
+		return err
+	}
+}
+
+// FnContext generate the query as a function honouring the context
+func (q UpsertQuery) FnContext() func(ctx context.Context, tx UpsertContextQuerier, i interface{}) error {
+	qs := q.String()
+	buildErr := q.Err()
+	return func(ctx context.Context, tx UpsertContextQuerier, i interface{}) error {
+		if buildErr != nil {
+			return buildErr
+		}
+		_, err := q.run(ctx, OpUpsert, qs, []interface{}{i}, func(ctx context.Context) (Result, error) {
+			return unknownRows(tx.UpsertContext(ctx, qs, i))
+		})
+		return err
+	}
+}
+
+// UpsertQueryOptions optional arguments to create a new upsert query
+type UpsertQueryOptions struct {
+	MakeQuery func(args MakeUpsertQueryArgs) string
+	Dialect   Dialect
+	Quote     QuoteMode
+}
+
+// NewUpsert construct a new upsert query
+func NewUpsert(
+	tableName string,
+	i interface{},
+	opts ...UpsertQueryOptions,
+) UpsertQuery {
+	fields, err := getFieldsByTag("db", i)
+
+	var options UpsertQueryOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	quoter := Quoter{
+		Dialect: dialectOrDefault(options.Dialect),
+		Mode:    options.Quote,
+	}
+	// a struct without a primary key has no conflict target, reported by
+	// conflictErr unless one is set
+	primaryKey, _ := primaryKeyOf(tableName, fields, quoter)
+
+	q := UpsertQuery{
+		query: query{
+			tableName: tableName,
+			columns:   columnsOf(fields, nil),
+			err:       err,
+			dialect:   dialectOrDefault(options.Dialect),
+			quoter:    quoter,
+			valueFields: Columns{
+				TableName: tableName,
+				Fields:    columnsOf(fields, field.insertable),
+				Quoter:    quoter,
+			},
+			returnFields: Columns{
+				TableName: tableName,
+				Fields:    columnsOf(fields, field.returnable),
+				Quoter:    quoter,
+			},
+		},
+		conflictFields: primaryKey,
+		updateFields: Columns{
+			TableName: tableName,
+			Fields:    columnsOf(fields, field.upsertable),
+			Quoter:    quoter,
+		},
+		makeQuery: makeUpsertQuery,
+	}
+
+	if options.MakeQuery != nil {
+		q.makeQuery = options.MakeQuery
+	}
+
+	return q
+}
+
+// makeUpsertQuery render an upsert query for the dialect of the args,
+// using ON CONFLICT, ON DUPLICATE KEY or MERGE as the dialect supports
+func makeUpsertQuery(args MakeUpsertQueryArgs) string {
+	returning := returningStyle(args.Dialect, args.ReturnFields)
+
+	switch dialectOrDefault(args.Dialect).Name() {
+	case "mysql":
+		if args.DoNothing || len(args.UpdateFields.Fields) == 0 {
+			return fmt.Sprintf(
+				templUpsertIgnore,
+				args.Quoter.Ident(args.TableName),
+				args.Values.Quoted().Joined(),
+				args.Values.AsParams().Joined(),
+			)
+		}
+
+		var assignments []string
+		for _, c := range args.UpdateFields.Fields {
+			c = args.Quoter.Ident(c)
+			assignments = append(assignments, fmt.Sprintf("%s=VALUES(%s)", c, c))
+		}
+
+		return fmt.Sprintf(
+			templUpsertDuplicateKey,
+			args.Quoter.Ident(args.TableName),
+			args.Values.Quoted().Joined(),
+			args.Values.AsParams().Joined(),
+			strings.Join(assignments, ", "),
+		)
+
+	case "sqlserver":
+		return makeMergeQuery(args, returning)
+	}
+
+	if returning == ReturningNone {
+		return fmt.Sprintf(
+			templUpsertNoReturn,
+			args.Quoter.Ident(args.TableName),
+			args.Values.Quoted().Joined(),
+			args.Values.AsParams().Joined(),
+			conflictClause(args),
+		)
+	}
+
+	return fmt.Sprintf(
+		templUpsert,
+		args.Quoter.Ident(args.TableName),
+		args.Values.Quoted().Joined(),
+		args.Values.AsParams().Joined(),
+		conflictClause(args),
+		args.ReturnFields.AsSelects().Joined(),
+	)
+}
+
+// conflictClause render the conflict target and action of an upsert
+func conflictClause(args MakeUpsertQueryArgs) string {
+	var target string
+	if len(args.ConflictFields.Fields) > 0 {
+		target = fmt.Sprintf("(%s) ", args.ConflictFields.Quoted().Joined())
+	}
+
+	if args.DoNothing || len(args.UpdateFields.Fields) == 0 {
+		return target + "DO NOTHING"
+	}
+
+	return fmt.Sprintf(
+		templConflictUpdate,
+		target,
+		args.UpdateFields.AsExcludedAssignments().Joined(),
+	)
+}
+
+// makeMergeQuery render an upsert as a MERGE statement matching the
+// conflict columns of the target table against the inserted values
+func makeMergeQuery(args MakeUpsertQueryArgs, returning ReturningStyle) string {
+	var matches []string
+	for _, c := range args.ConflictFields.Fields {
+		c = args.Quoter.Ident(c)
+		matches = append(matches, fmt.Sprintf("target.%s=source.%s", c, c))
+	}
+
+	on := "1=0"
+	if len(matches) > 0 {
+		on = strings.Join(matches, " AND ")
+	}
+
+	var matched string
+	if !args.DoNothing && len(args.UpdateFields.Fields) > 0 && len(matches) > 0 {
+		matched = fmt.Sprintf(
+			"\n\tWHEN MATCHED THEN UPDATE SET\n\t\t%s",
+			args.UpdateFields.AsAssignmentsFrom("source").Joined(),
+		)
+	}
+
+	var output string
+	if returning != ReturningNone {
+		output = fmt.Sprintf(
+			"\n\tOUTPUT %s",
+			args.ReturnFields.AsQualified("INSERTED").Joined(),
+		)
+	}
+
+	return fmt.Sprintf(
+		templUpsertMerge,
+		args.Quoter.Ident(args.TableName),
+		args.Values.AsParams().Joined(),
+		args.Values.Quoted().Joined(),
+		on,
+		matched,
+		args.Values.Quoted().Joined(),
+		args.Values.AsQualified("source").Joined(),
+		output,
+	)
+}